@@ -0,0 +1,132 @@
+// Package app bundles the lifecycle of a command: startup output, config
+// loading, signal handling and graceful shutdown.
+//
+//	a := app.New("server")
+//	a.Start()
+//	a.OnShutdown("http", srv.Shutdown)
+//	go srv.ListenAndServe()
+//	a.Wait()
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+)
+
+const DefaultShutdownTimeout = 10 * time.Second
+
+// Hook is called on shutdown. ctx is cancelled after the shutdown timeout.
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   Hook
+}
+
+// App cancels its context on SIGINT or SIGTERM and runs the shutdown hooks.
+type App struct {
+	Name            string
+	ShutdownTimeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	hooks []hook
+	once  sync.Once
+	err   error
+}
+
+// New returns an app whose context is cancelled on SIGINT or SIGTERM.
+func New(name string) *App {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	return &App{Name: name, ShutdownTimeout: DefaultShutdownTimeout, ctx: ctx, cancel: cancel}
+}
+
+// Start prints the start banner, all flags with secrets masked and the go
+// env vars.
+func (a *App) Start() {
+	log.Plain("*** Start", a.Name)
+	log.Flags(true)
+	log.Newline()
+}
+
+// Context is cancelled on SIGINT, SIGTERM or Shutdown.
+func (a *App) Context() context.Context {
+	return a.ctx
+}
+
+// OnShutdown registers fn. Hooks run in reverse order of registration.
+func (a *App) OnShutdown(name string, fn Hook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hook{name: name, fn: fn})
+}
+
+// Wait blocks until the context is cancelled and shuts down.
+func (a *App) Wait() error {
+	<-a.ctx.Done()
+	return a.Shutdown()
+}
+
+// Shutdown cancels the context, runs all hooks within ShutdownTimeout and
+// flushes all loggers. Errors of hooks are logged and returned joined.
+// Subsequent calls return the result of the first call.
+func (a *App) Shutdown() error {
+	a.once.Do(func() {
+		a.cancel()
+		log.Info("*** Shutdown", a.Name)
+
+		timeout := a.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = DefaultShutdownTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		a.mu.Lock()
+		hooks := append([]hook{}, a.hooks...)
+		a.mu.Unlock()
+
+		errs := []error{}
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := run(ctx, hooks[i]); err != nil {
+				log.Err(err, "shutdown of", hooks[i].name, "failed")
+				errs = append(errs, err)
+			}
+		}
+		log.FlushAll()
+		a.err = errors.Join(errs...)
+	})
+	return a.err
+}
+
+// Exit shuts down and exits with code, or 1 if shutdown failed.
+func (a *App) Exit(code int) {
+	if err := a.Shutdown(); err != nil && code == 0 {
+		code = 1
+	}
+	os.Exit(code)
+}
+
+// run calls h and gives up once ctx is done. The hook keeps running in the
+// background in that case.
+func run(ctx context.Context, h hook) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New(h.name + ": " + ctx.Err().Error())
+	}
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/env"
+	"github.com/nice-pink/goutil/pkg/log"
+	"github.com/nice-pink/goutil/pkg/log/logtest"
+)
+
+var (
+	_ = flag.Int("test-app-db-port", 0, "")
+	_ = flag.Bool("test-app-debug", false, "")
+	_ = flag.String("test-app-api-token", "", "")
+)
+
+type dbConfig struct {
+	Host string `json:"host" env:"TEST_APP_DB_HOST"`
+	Port int    `json:"port" flag:"test-app-db-port"`
+}
+
+type testConfig struct {
+	Name    string        `json:"name"`
+	Timeout time.Duration `json:"-" env:"TEST_APP_TIMEOUT"`
+	Tags    []string      `json:"tags" env:"TEST_APP_TAGS"`
+	Debug   bool          `json:"debug" flag:"test-app-debug"`
+	Db      dbConfig      `json:"db"`
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("name: app\ntags: [a]\ndb:\n  host: file\n  port: 1\n"), 0644)
+
+	t.Setenv("TEST_APP_DB_HOST", "env")
+	t.Setenv("TEST_APP_TIMEOUT", "3s")
+	t.Setenv("TEST_APP_TAGS", "b, c")
+	flag.Set("test-app-db-port", "5432")
+
+	cfg := testConfig{Debug: true}
+	if err := LoadConfig(path, &cfg); err != nil {
+		t.Fatal("TestLoadConfig:: unexpected error", err)
+	}
+	if cfg.Name != "app" || cfg.Db.Host != "env" || cfg.Db.Port != 5432 || cfg.Timeout != 3*time.Second {
+		t.Error("TestLoadConfig:: unexpected config", cfg)
+	}
+	if strings.Join(cfg.Tags, ",") != "b,c" || !cfg.Debug {
+		t.Error("TestLoadConfig:: unexpected tags or overwritten debug flag", cfg)
+	}
+
+	t.Setenv("TEST_APP_TIMEOUT", "soon")
+	if err := LoadConfig("", &cfg); err == nil || !strings.Contains(err.Error(), "TEST_APP_TIMEOUT") {
+		t.Error("TestLoadConfig:: expected error of invalid duration", err)
+	}
+	if err := LoadConfig("", cfg); err == nil {
+		t.Error("TestLoadConfig:: expected error for non pointer")
+	}
+}
+
+type requiredConfig struct {
+	Token  string `json:"token" env:"TEST_APP_TOKEN" required:"true"`
+	Region string `json:"region" env:"TEST_APP_REGION" default:"eu"`
+	Port   int    `json:"port" env:"TEST_APP_PORT" flag:"test-app-db-port" required:"true"`
+	Cache  struct {
+		Size int `env:"SIZE"`
+	} `json:"-" env:"TEST_APP_CACHE"`
+}
+
+func TestLoadConfigEnvTags(t *testing.T) {
+	flag.Set("test-app-db-port", "5432")
+	t.Setenv("TEST_APP_CACHE_SIZE", "64")
+
+	// token is missing, port is set by flag
+	cfg := requiredConfig{}
+	err := LoadConfig("", &cfg)
+	if !errors.Is(err, env.ErrMissing) || !strings.Contains(err.Error(), "TEST_APP_TOKEN") || strings.Contains(err.Error(), "TEST_APP_PORT") {
+		t.Error("TestLoadConfigEnvTags:: expected missing token only", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"token":"file"}`), 0644)
+	cfg = requiredConfig{}
+	if err := LoadConfig(path, &cfg); err != nil {
+		t.Fatal("TestLoadConfigEnvTags:: unexpected error", err)
+	}
+	if cfg.Token != "file" || cfg.Region != "eu" || cfg.Port != 5432 || cfg.Cache.Size != 64 {
+		t.Error("TestLoadConfigEnvTags:: unexpected config", cfg)
+	}
+}
+
+type defaultsConfig struct {
+	Debug bool   `json:"debug" env:"TEST_APP_DEBUG" default:"true"`
+	Port  int    `json:"port" env:"TEST_APP_PORT" default:"8080"`
+	Host  string `json:"host" default:"localhost"`
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"debug":false,"port":0}`), 0644)
+
+	// zero values of the file overwrite defaults
+	cfg := defaultsConfig{}
+	if err := LoadConfig(path, &cfg); err != nil {
+		t.Fatal("TestLoadConfigDefaults:: unexpected error", err)
+	}
+	if cfg.Debug || cfg.Port != 0 || cfg.Host != "localhost" {
+		t.Error("TestLoadConfigDefaults:: unexpected config", cfg)
+	}
+}
+
+func TestStartMasksSecrets(t *testing.T) {
+	r := logtest.Capture(t)
+	flag.Set("test-app-api-token", "s3cr3t-value")
+
+	New("test").Start()
+	r.AssertLogged(t, log.LLInfo, "*** Start test")
+	for _, e := range r.Entries() {
+		if strings.Contains(e.Message, "s3cr3t-value") {
+			t.Error("TestStartMasksSecrets:: secret printed", e.Message)
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	r := logtest.Capture(t)
+
+	a := New("test")
+	a.ShutdownTimeout = 50 * time.Millisecond
+	order := []string{}
+	// runs last and exceeds the timeout
+	a.OnShutdown("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	a.OnShutdown("first", func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	a.OnShutdown("broken", func(ctx context.Context) error {
+		order = append(order, "broken")
+		return errors.New("broken")
+	})
+
+	// signal
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		t.Skip("TestShutdown:: cannot signal process", err)
+	}
+	select {
+	case <-a.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("TestShutdown:: context should be cancelled on SIGTERM")
+	}
+
+	err := a.Wait()
+	if err == nil || !strings.Contains(err.Error(), "broken") || !strings.Contains(err.Error(), "slow: context deadline exceeded") {
+		t.Error("TestShutdown:: unexpected error", err)
+	}
+	if strings.Join(order, ",") != "broken,first" {
+		t.Error("TestShutdown:: hooks should run in reverse order", order)
+	}
+	if a.Shutdown() != err {
+		t.Error("TestShutdown:: shutdown should run once")
+	}
+	r.AssertLogged(t, log.LLError, "shutdown of broken failed")
+}
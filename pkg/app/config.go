@@ -0,0 +1,79 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/data"
+	"github.com/nice-pink/goutil/pkg/env"
+)
+
+// LoadConfig fills the struct pointed to by cfg. Later sources overwrite
+// earlier ones:
+//
+//  1. defaults of the `default` tag, see env.Defaults
+//  2. the json or yaml file at path, skipped if path is empty
+//  3. env vars loaded by env.Overlay, see the tags of env.Load
+//  4. flags named by the `flag` tag of a field, if set on the command line
+//
+// Flag values are parsed like env vars. Nested structs are walked. Required
+// fields may be set by any source and are missing if they are still zero.
+func LoadConfig(path string, cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	if err := env.Defaults(cfg); err != nil {
+		return err
+	}
+	if path != "" {
+		if err := data.ReadJsonOrYaml(path, cfg); err != nil {
+			return err
+		}
+	}
+
+	set := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	// flags before env too, so required fields see them
+	if err := applyFlags(v.Elem(), set); err != nil {
+		return err
+	}
+	if err := env.Overlay("", cfg); err != nil {
+		return err
+	}
+	return applyFlags(v.Elem(), set)
+}
+
+// intern
+
+// applyFlags sets fields tagged with `flag` to the values of set flags.
+func applyFlags(v reflect.Value, flags map[string]string) error {
+	errs := []error{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+			errs = append(errs, applyFlags(fv, flags))
+			continue
+		}
+
+		if name := sf.Tag.Get("flag"); name != "" {
+			if value, ok := flags[name]; ok {
+				if err := env.Unmarshal(value, fv.Addr().Interface()); err != nil {
+					errs = append(errs, errors.New("flag "+name+": "+err.Error()))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
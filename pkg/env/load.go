@@ -0,0 +1,270 @@
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissing is wrapped by errors of required but unset env vars.
+var ErrMissing = errors.New("required but not set")
+
+// VarError is the error of a single env var.
+type VarError struct {
+	Name string
+	Err  error
+}
+
+func (e *VarError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e *VarError) Unwrap() error {
+	return e.Err
+}
+
+// Load populates the struct pointed to by cfg from env vars. Fields are
+// configured by tags:
+//
+//	Host    string            `env:"HOST" default:"localhost"`
+//	Token   string            `env:"TOKEN" required:"true"`
+//	Timeout time.Duration     `env:"TIMEOUT" default:"5s"`
+//	Hosts   []string          `env:"HOSTS"`  // a,b,c
+//	Labels  map[string]string `env:"LABELS"` // k1:v1,k2:v2
+//	Db      DbConfig          `env:"DB"`     // fields read as DB_<NAME>
+//
+// Fields without env tag are skipped, nested structs without env tag are
+// read without prefix. Unset or empty vars fall back to the default. Types
+// implementing encoding.TextUnmarshaler are parsed by UnmarshalText. All
+// missing and invalid vars are returned as joined *VarError.
+func Load(cfg any) error {
+	return LoadPrefix("", cfg)
+}
+
+// LoadPrefix is like Load, all names are prefixed by prefix, e.g. APP_.
+func LoadPrefix(prefix string, cfg any) error {
+	return walk(prefix, cfg, modeLoad)
+}
+
+// Defaults sets all fields with a default tag, with or without env tag.
+// Together with Overlay it layers env vars over other sources, e.g. a config
+// file read in between.
+func Defaults(cfg any) error {
+	return walk("", cfg, modeDefaults)
+}
+
+// Overlay is like LoadPrefix, but fields of unset or empty vars keep their
+// value and defaults are not applied. Required fields are missing if they
+// are still zero.
+func Overlay(prefix string, cfg any) error {
+	return walk(prefix, cfg, modeOverlay)
+}
+
+// Unmarshal parses value into the variable pointed to by target using the
+// rules of Load.
+func Unmarshal(value string, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("env: target must be a non-nil pointer")
+	}
+	return parse(v.Elem(), value)
+}
+
+// intern
+
+var (
+	durationType  = reflect.TypeOf(time.Duration(0))
+	unmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type mode int
+
+const (
+	modeLoad mode = iota
+	modeDefaults
+	modeOverlay
+)
+
+func walk(prefix string, cfg any, m mode) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("env: cfg must be a pointer to a struct")
+	}
+	return errors.Join(load(prefix, v.Elem(), m)...)
+}
+
+func load(prefix string, v reflect.Value, m mode) []error {
+	errs := []error{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		name, tagged := sf.Tag.Lookup("env")
+
+		// nested
+		if isStruct(fv.Type()) && !isText(fv.Type()) {
+			nested := prefix
+			if tagged && name != "" {
+				nested += name + "_"
+			}
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			errs = append(errs, load(nested, fv, m)...)
+			continue
+		}
+
+		if m == modeDefaults && name == "" {
+			// defaults don't need an env var
+			name = sf.Name
+		} else if !tagged || name == "" {
+			continue
+		}
+		name = prefix + name
+		var value string
+		var ok bool
+		switch m {
+		case modeDefaults:
+			value, ok = sf.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+		case modeOverlay:
+			value = os.Getenv(name)
+			ok = value != ""
+			if !ok && !fv.IsZero() {
+				continue
+			}
+		default:
+			value = os.Getenv(name)
+			ok = value != ""
+			if !ok {
+				value, ok = sf.Tag.Lookup("default")
+			}
+		}
+		if !ok {
+			if required, _ := strconv.ParseBool(sf.Tag.Get("required")); required {
+				errs = append(errs, &VarError{Name: name, Err: ErrMissing})
+			}
+			continue
+		}
+		if err := parse(fv, value); err != nil {
+			errs = append(errs, &VarError{Name: name, Err: err})
+		}
+	}
+	return errs
+}
+
+func parse(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := parse(ptr.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if isText(v.Type()) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := split(value)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parse(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, item := range split(value) {
+			k, val, ok := strings.Cut(item, ":")
+			if !ok {
+				return errors.New("invalid map item " + strconv.Quote(item) + ", expected key:value")
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := parse(key, strings.TrimSpace(k)); err != nil {
+				return fmt.Errorf("key %s: %w", k, err)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := parse(elem, strings.TrimSpace(val)); err != nil {
+				return fmt.Errorf("value of %s: %w", k, err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+	return nil
+}
+
+// split splits comma separated items and drops empty ones.
+func split(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct)
+}
+
+func isText(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		return t.Implements(unmarshalType)
+	}
+	return reflect.PointerTo(t).Implements(unmarshalType)
+}
@@ -0,0 +1,125 @@
+package env
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level " + string(text))
+	}
+	return nil
+}
+
+type dbConfig struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port int    `env:"PORT" required:"true"`
+}
+
+type testConfig struct {
+	Name    string         `env:"NAME" required:"true"`
+	Timeout time.Duration  `env:"TIMEOUT" default:"5s"`
+	Hosts   []string       `env:"HOSTS"`
+	Ports   []int          `env:"PORTS"`
+	Weights map[string]int `env:"WEIGHTS"`
+	Level   level          `env:"LEVEL"`
+	Ip      net.IP         `env:"IP"`
+	Debug   *bool          `env:"DEBUG"`
+	Db      dbConfig       `env:"DB"`
+	Cache   struct {
+		Size int `env:"CACHE_SIZE" default:"10"`
+	}
+	ignored string
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("APP_NAME", "app")
+	t.Setenv("APP_HOSTS", "a, b,,c")
+	t.Setenv("APP_PORTS", "80,443")
+	t.Setenv("APP_WEIGHTS", "a:1, b:2")
+	t.Setenv("APP_LEVEL", "high")
+	t.Setenv("APP_IP", "10.0.0.1")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_DB_PORT", "5432")
+
+	cfg := testConfig{}
+	if err := LoadPrefix("APP_", &cfg); err != nil {
+		t.Fatal("TestLoad:: unexpected error", err)
+	}
+	if cfg.Name != "app" || cfg.Timeout != 5*time.Second || strings.Join(cfg.Hosts, "|") != "a|b|c" {
+		t.Error("TestLoad:: unexpected values", cfg)
+	}
+	if len(cfg.Ports) != 2 || cfg.Ports[1] != 443 || cfg.Weights["b"] != 2 || cfg.Level != 2 {
+		t.Error("TestLoad:: unexpected parsed values", cfg)
+	}
+	if cfg.Ip.String() != "10.0.0.1" || cfg.Debug == nil || !*cfg.Debug {
+		t.Error("TestLoad:: unexpected text unmarshaler or pointer", cfg.Ip, cfg.Debug)
+	}
+	if cfg.Db.Host != "localhost" || cfg.Db.Port != 5432 || cfg.Cache.Size != 10 {
+		t.Error("TestLoad:: unexpected nested values", cfg.Db, cfg.Cache)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	t.Setenv("DB_HOST", "")
+	t.Setenv("TIMEOUT", "1m")
+
+	cfg := testConfig{}
+	if err := Defaults(&cfg); err != nil || cfg.Timeout != 5*time.Second || cfg.Db.Host != "localhost" || cfg.Cache.Size != 10 {
+		t.Fatal("TestOverlay:: unexpected defaults", cfg, err)
+	}
+
+	// e.g. read from a file, zero values included
+	cfg.Name, cfg.Db.Host, cfg.Db.Port, cfg.Cache.Size = "file", "db", 5432, 0
+	if err := Overlay("", &cfg); err != nil {
+		t.Fatal("TestOverlay:: required values are set", err)
+	}
+	if cfg.Name != "file" || cfg.Timeout != time.Minute || cfg.Db.Host != "db" || cfg.Cache.Size != 0 {
+		t.Error("TestOverlay:: unset vars should keep values", cfg)
+	}
+
+	cfg.Name = ""
+	if err := Overlay("", &cfg); !errors.Is(err, ErrMissing) || !strings.Contains(err.Error(), "NAME") {
+		t.Error("TestOverlay:: expected missing name", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Setenv("TIMEOUT", "soon")
+	t.Setenv("LEVEL", "medium")
+	t.Setenv("WEIGHTS", "a=1")
+
+	cfg := testConfig{}
+	err := Load(&cfg)
+	if err == nil {
+		t.Fatal("TestLoadErrors:: expected error")
+	}
+	if !errors.Is(err, ErrMissing) {
+		t.Error("TestLoadErrors:: expected missing error", err)
+	}
+	for _, name := range []string{"NAME: required", "DB_PORT: required", "TIMEOUT:", "LEVEL: unknown level medium", "WEIGHTS:"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Error("TestLoadErrors:: missing", name, "in", err)
+		}
+	}
+
+	var varErr *VarError
+	if !errors.As(err, &varErr) || varErr.Name != "NAME" {
+		t.Error("TestLoadErrors:: expected first var error of NAME", varErr)
+	}
+
+	if err := Load(cfg); err == nil {
+		t.Error("TestLoadErrors:: expected error for non pointer")
+	}
+}
@@ -240,6 +240,7 @@ func ListFiles(folder string, olderThanSeconds int64, ignoreHiddenFiles bool) []
 		// check time
 		if olderThanSeconds <= 0 {
 			filenames = append(filenames, filepath)
+			continue
 		}
 
 		if IsOlderThan(fileInfo.ModTime(), olderThanSeconds) {
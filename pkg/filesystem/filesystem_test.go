@@ -0,0 +1,36 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"new", "old", ".hidden"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+	os.Mkdir(filepath.Join(dir, "folder"), 0755)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "old"), old, old)
+
+	// all files, each listed once
+	files := ListFiles(dir, 0, true)
+	slices.Sort(files)
+	if !slices.Equal(files, []string{filepath.Join(dir, "new"), filepath.Join(dir, "old")}) {
+		t.Error("TestListFiles:: unexpected files", files)
+	}
+
+	files = ListFiles(dir, 0, false)
+	if len(files) != 3 {
+		t.Error("TestListFiles:: expected hidden file", files)
+	}
+
+	files = ListFiles(dir, 60, true)
+	if !slices.Equal(files, []string{filepath.Join(dir, "old")}) {
+		t.Error("TestListFiles:: expected only old file", files)
+	}
+}
@@ -0,0 +1,143 @@
+package log
+
+import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// field keys added by enrichment
+const (
+	CallerKey = "caller"
+	StackKey  = "stack"
+	ErrorKey  = "error"
+	CauseKey  = "cause" // cause.1, cause.2, ...
+)
+
+const pkgPrefix = "github.com/nice-pink/goutil/pkg/log."
+
+// caller
+
+// SetCaller adds the file:line of the calling code to every line.
+func (l *Logger) SetCaller(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.caller = enabled
+}
+
+// SetStack adds a stack trace of the calling code to critical lines.
+func (l *Logger) SetStack(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stack = enabled
+}
+
+// caller returns `dir/file.go:line` of the first frame outside of this
+// package and log/slog.
+func caller() string {
+	frames := callerFrames()
+	if len(frames) == 0 {
+		return ""
+	}
+	frame := frames[0]
+	return filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)) + ":" + strconv.Itoa(frame.Line)
+}
+
+// stack returns the stack starting at the calling code, one
+// `function\n\tfile:line` entry per frame.
+func stack() string {
+	entries := []string{}
+	for _, frame := range callerFrames() {
+		entries = append(entries, frame.Function+"\n\t"+frame.File+":"+strconv.Itoa(frame.Line))
+	}
+	return strings.Join(entries, "\n")
+}
+
+// callerFrames returns the frames of the calling code, frames of the
+// logging packages are skipped.
+func callerFrames() []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	result := []runtime.Frame{}
+	for {
+		frame, more := frames.Next()
+		if len(result) > 0 || !internalFrame(frame) {
+			result = append(result, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+func internalFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(frame.Function, pkgPrefix) || strings.HasPrefix(frame.Function, "log/slog.")
+}
+
+// error chain
+
+// errorCauses returns the errors wrapped by err depth first. errors.Join and
+// fmt.Errorf with multiple %w are followed into every branch.
+func errorCauses(err error) []error {
+	causes := []error{}
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, c := range e.Unwrap() {
+				if c != nil {
+					causes = append(causes, c)
+					walk(c)
+				}
+			}
+		default:
+			if c := errors.Unwrap(err); c != nil {
+				causes = append(causes, c)
+				walk(c)
+			}
+		}
+	}
+	if err != nil {
+		walk(err)
+	}
+	return causes
+}
+
+// errorFields renders err as error field followed by cause.N fields.
+func errorFields(err error) []field {
+	if err == nil {
+		return nil
+	}
+	fields := []field{{key: ErrorKey, value: err.Error()}}
+	for i, c := range errorCauses(err) {
+		fields = append(fields, field{key: CauseKey + "." + strconv.Itoa(i+1), value: c.Error()})
+	}
+	return fields
+}
+
+// withError returns e with the error fields of e.Err added to a copy of
+// e.Data. Err of the returned entry is nil.
+func (e Entry) withError() Entry {
+	if e.Err == nil {
+		return e
+	}
+	data := maps.Clone(e.Data)
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	for _, f := range errorFields(e.Err) {
+		data[f.key] = f.value
+	}
+	e.Data = data
+	e.Err = nil
+	return e
+}
@@ -0,0 +1,134 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestErrorCauses(t *testing.T) {
+	err := fmt.Errorf("save: %w", errors.Join(io.EOF, fmt.Errorf("close: %w", io.ErrClosedPipe)))
+
+	fields := errorFields(err)
+	got := []string{}
+	for _, f := range fields {
+		got = append(got, f.key+"="+f.value.(string))
+	}
+	want := []string{
+		"error=" + err.Error(),
+		"cause.1=" + errors.Unwrap(err).Error(),
+		"cause.2=EOF",
+		"cause.3=close: io: read/write on closed pipe",
+		"cause.4=io: read/write on closed pipe",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Error("TestErrorCauses:: unexpected fields", got)
+	}
+
+	if errorFields(nil) != nil {
+		t.Error("TestErrorCauses:: nil error should have no fields")
+	}
+}
+
+func TestLoggerCaller(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.SetCaller(true)
+	l.Info("hello")
+	if !strings.Contains(buf.String(), " caller=log/caller_test.go:") {
+		t.Error("TestLoggerCaller:: expected caller of test", buf.String())
+	}
+
+	// package level functions
+	buf.Reset()
+	prev := Default()
+	defer SetDefault(prev)
+	SetDefault(l)
+	Info("hello")
+	if !strings.Contains(buf.String(), " caller=log/caller_test.go:") {
+		t.Error("TestLoggerCaller:: expected caller of package function", buf.String())
+	}
+}
+
+func TestLoggerStack(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.SetStack(true)
+	l.Error("error")
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Error("TestLoggerStack:: no stack expected below critical", buf.String())
+	}
+
+	buf.Reset()
+	l.Critical("critical")
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) < 3 || !strings.HasSuffix(lines[1], ".TestLoggerStack") {
+		t.Error("TestLoggerStack:: stack should start at caller", buf.String())
+	}
+}
+
+func TestLoggerErrChain(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.Err(fmt.Errorf("read: %w", io.EOF), "failed")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[1] != "read: EOF" || lines[2] != "\tcause.1: EOF" {
+		t.Error("TestLoggerErrChain:: unexpected text lines", lines)
+	}
+
+	buf.Reset()
+	l.SetEncoding(EncodingLogfmt)
+	l.Err(fmt.Errorf("read: %w", io.EOF), "failed")
+	if !strings.HasSuffix(buf.String(), ` msg=failed error="read: EOF" cause.1=EOF`+"\n") {
+		t.Error("TestLoggerErrChain:: unexpected logfmt line", buf.String())
+	}
+}
+
+func TestRLogErrAndCaller(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+	prev := Default()
+	defer SetDefault(prev)
+	SetDefault(NewLogger(io.Discard, LLVerbose))
+
+	var collector bytes.Buffer
+	rlog := NewRLog("", 0, "error", "", true)
+	rlog.AddCaller = true
+	rlog.AddStack = true
+	rlog.AddDestination("collector", LLError, NewWriterSink(&collector, JsonFormatter(rlog.Keys, "", true)))
+
+	rlog.ErrD(fmt.Errorf("query: %w", io.ErrUnexpectedEOF), map[string]interface{}{"table": "users"}, "failed")
+	rlog.Critical("down")
+
+	lines := strings.Split(strings.TrimSuffix(collector.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatal("TestRLogErrAndCaller:: expected 2 entries", lines)
+	}
+
+	var data map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &data)
+	if data["error"] != "query: unexpected EOF" || data["cause.1"] != "unexpected EOF" || data["table"] != "users" {
+		t.Error("TestRLogErrAndCaller:: unexpected error fields", data)
+	}
+	if caller, _ := data["caller"].(string); !strings.HasPrefix(caller, "log/caller_test.go:") {
+		t.Error("TestRLogErrAndCaller:: unexpected caller", data["caller"])
+	}
+	if _, ok := data["stack"]; ok {
+		t.Error("TestRLogErrAndCaller:: stack only expected for critical", data)
+	}
+
+	data = nil
+	json.Unmarshal([]byte(lines[1]), &data)
+	if stack, _ := data["stack"].(string); !strings.Contains(stack, "TestRLogErrAndCaller") {
+		t.Error("TestRLogErrAndCaller:: expected stack of critical entry", data)
+	}
+}
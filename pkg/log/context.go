@@ -0,0 +1,144 @@
+package log
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/nice-pink/goutil/pkg/random"
+)
+
+// common field keys
+
+const (
+	RequestIdKey = "request_id"
+	TraceIdKey   = "trace_id"
+	SpanIdKey    = "span_id"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a context carrying fields. Fields already in ctx are
+// kept unless overwritten.
+func WithFields(ctx context.Context, fields map[string]any) context.Context {
+	merged := maps.Clone(Fields(ctx))
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	maps.Copy(merged, fields)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithField returns a context carrying key=value.
+func WithField(ctx context.Context, key string, value any) context.Context {
+	return WithFields(ctx, map[string]any{key: value})
+}
+
+// WithRequestId returns a context carrying the request_id field.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return WithField(ctx, RequestIdKey, requestId)
+}
+
+// WithTrace returns a context carrying the trace_id and span_id fields.
+func WithTrace(ctx context.Context, traceId, spanId string) context.Context {
+	fields := map[string]any{TraceIdKey: traceId}
+	if spanId != "" {
+		fields[SpanIdKey] = spanId
+	}
+	return WithFields(ctx, fields)
+}
+
+// Fields returns the fields of ctx. The map must not be modified.
+func Fields(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]any)
+	return fields
+}
+
+// FromContext returns the default logger with the fields of ctx.
+func FromContext(ctx context.Context) *Logger {
+	return Default().WithContext(ctx)
+}
+
+// WithContext returns a copy of the logger with the fields of ctx.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields)
+}
+
+// http
+
+// Middleware adds request_id, trace_id and span_id of incoming requests to
+// the request context. The request id is read from the X-Request-Id header
+// or generated, trace and span from a W3C traceparent header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get("X-Request-Id")
+		if requestId == "" {
+			requestId = random.RandStringAlphaNum(16)
+		}
+		ctx := WithRequestId(r.Context(), requestId)
+
+		if traceId, spanId, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+			ctx = WithTrace(ctx, traceId, spanId)
+		}
+
+		w.Header().Set("X-Request-Id", requestId)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// parseTraceparent parses `version-traceid-parentid-flags`.
+func parseTraceparent(header string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// rlog
+
+func (l *RLog) VerboseCtx(ctx context.Context, logs ...any) {
+	l.log(LLVerbose, Fields(ctx), logs)
+}
+
+func (l *RLog) DebugCtx(ctx context.Context, logs ...any) {
+	l.log(LLDebug, Fields(ctx), logs)
+}
+
+func (l *RLog) InfoCtx(ctx context.Context, logs ...any) {
+	l.log(LLInfo, Fields(ctx), logs)
+}
+
+func (l *RLog) WarnCtx(ctx context.Context, logs ...any) {
+	l.log(LLWarn, Fields(ctx), logs)
+}
+
+func (l *RLog) ErrorCtx(ctx context.Context, logs ...any) {
+	l.log(LLError, Fields(ctx), logs)
+}
+
+func (l *RLog) CriticalCtx(ctx context.Context, logs ...any) {
+	l.log(LLCritical, Fields(ctx), logs)
+}
+
+// contextData merges the fields of ctx and data. Data overwrite fields.
+func contextData(ctx context.Context, data map[string]interface{}) map[string]interface{} {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return data
+	}
+	merged := maps.Clone(fields)
+	maps.Copy(merged, data)
+	return merged
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	ctx := WithRequestId(context.Background(), "req1")
+	ctx = WithTrace(ctx, "trace1", "span1")
+	ctx = WithField(ctx, "user", "a")
+
+	var buf bytes.Buffer
+	NewLogger(&buf, LLInfo).WithContext(ctx).Info("hello")
+	if !strings.HasSuffix(buf.String(), " INFO: hello request_id=req1 span_id=span1 trace_id=trace1 user=a\n") {
+		t.Error("TestContextFields:: unexpected logger output", buf.String())
+	}
+
+	buf.Reset()
+	slog.New(NewConsoleHandler(NewLogger(&buf, LLInfo))).InfoContext(ctx, "hello", "id", 1)
+	if !strings.HasSuffix(buf.String(), " INFO: hello request_id=req1 span_id=span1 trace_id=trace1 user=a id=1\n") {
+		t.Error("TestContextFields:: unexpected slog output", buf.String())
+	}
+
+	// rlog
+	var collector bytes.Buffer
+	rlog := NewRLog("", 0, "critical", "", true)
+	rlog.AddDestination("collector", LLInfo, NewWriterSink(&collector, JsonFormatter(rlog.Keys, "", true)))
+	rlog.InfoCtx(ctx, "hello")
+	if !strings.Contains(collector.String(), `"request_id":"req1"`) || !strings.Contains(collector.String(), `"trace_id":"trace1"`) {
+		t.Error("TestContextFields:: unexpected rlog output", collector.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var fields map[string]any
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = Fields(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if fields[TraceIdKey] != "4bf92f3577b34da6a3ce929d0e0e4736" || fields[SpanIdKey] != "00f067aa0ba902b7" {
+		t.Error("TestMiddleware:: unexpected trace fields", fields)
+	}
+	if id := rec.Header().Get("X-Request-Id"); id == "" || fields[RequestIdKey] != id {
+		t.Error("TestMiddleware:: unexpected request id", fields, id)
+	}
+}
@@ -0,0 +1,180 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/env"
+)
+
+// Env vars configuring the console output of the default logger at startup.
+const (
+	FormatEnv     = "LOG_FORMAT"      // text, logfmt or json
+	TimeFormatEnv = "LOG_TIME_FORMAT" // go time layout, e.g. 2006-01-02T15:04:05Z07:00
+	UtcEnv        = "LOG_UTC"         // true prints timestamps in utc
+)
+
+// encoding
+
+type Encoding int
+
+const (
+	EncodingText   Encoding = iota // 2006-01-02 15:04:05 INFO: msg key=value
+	EncodingLogfmt                 // time="2006-01-02 15:04:05" level=info msg=msg key=value
+	EncodingJson                   // {"message":"msg","severity":"INFO","timestamp":"..."}
+)
+
+func GetEncoding(encoding string) Encoding {
+	switch strings.ToLower(encoding) {
+	case "logfmt":
+		return EncodingLogfmt
+	case "json":
+		return EncodingJson
+	}
+	return EncodingText
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingLogfmt:
+		return "logfmt"
+	case EncodingJson:
+		return "json"
+	}
+	return "text"
+}
+
+// DefaultKeys returns the keys used by NewRLog and json console output.
+func DefaultKeys() Keys {
+	return Keys{
+		Message:   "message",
+		Timestamp: "timestamp",
+		Severity:  "severity",
+	}
+}
+
+func init() {
+	l := Default()
+	l.SetEncoding(GetEncoding(env.GetEnvString(FormatEnv, "")))
+	l.SetTimeFormat(env.GetEnvString(TimeFormatEnv, ""), env.GetEnvBool(UtcEnv, false))
+}
+
+// logger config
+
+// SetEncoding selects the console format of the logger.
+func (l *Logger) SetEncoding(encoding Encoding) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.encoding = encoding
+}
+
+func (l *Logger) Encoding() Encoding {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.encoding
+}
+
+// SetTimeFormat sets the layout of timestamps (time.DateTime if empty) and
+// if they are printed in utc.
+func (l *Logger) SetTimeFormat(layout string, isUtc bool) {
+	if layout == "" {
+		layout = time.DateTime
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timeFormat = layout
+	l.isUtc = isUtc
+}
+
+// SetKeys sets the keys of timestamp, message and severity for json output.
+// Empty keys keep the current value, like RLog.UpdateKeys.
+func (l *Logger) SetKeys(keys Keys) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if keys.Message != "" {
+		l.keys.Message = keys.Message
+	}
+	if keys.Severity != "" {
+		l.keys.Severity = keys.Severity
+	}
+	if keys.Timestamp != "" {
+		l.keys.Timestamp = keys.Timestamp
+	}
+}
+
+// fields
+
+// field is a key value pair keeping its position in the output.
+type field struct {
+	key   string
+	value any
+}
+
+// sortedFields returns fields sorted by key.
+func sortedFields(fields map[string]any) []field {
+	sorted := make([]field, 0, len(fields))
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		sorted = append(sorted, field{key: k, value: fields[k]})
+	}
+	return sorted
+}
+
+// renderFields renders fields as space separated key=value pairs.
+func renderFields(fields []field) string {
+	pairs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		pairs = append(pairs, f.key+"="+fieldValue(f.value))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// fieldValue quotes values which contain spaces, '=' or '"' or are empty.
+func fieldValue(v any) string {
+	value := fmt.Sprint(v)
+	if value == "" || strings.ContainsAny(value, " =\"\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// encoders
+
+func encodeLogfmt(ts string, level LogLevel, prefix, msg string, fields []field) string {
+	if prefix != "" {
+		msg = prefix + " " + msg
+	}
+	head := []field{{key: "time", value: ts}, {key: "level", value: level.String()}, {key: "msg", value: msg}}
+	return renderFields(append(head, fields...))
+}
+
+// encodeJson renders one json object. Later fields overwrite earlier ones,
+// timestamp, severity and message can't be overwritten.
+func encodeJson(keys Keys, ts string, level LogLevel, prefix, msg string, fields []field) string {
+	if prefix != "" {
+		msg = prefix + " " + msg
+	}
+	add := map[string]any{}
+	for _, f := range fields {
+		if err, ok := f.value.(error); ok {
+			add[f.key] = err.Error()
+			continue
+		}
+		add[f.key] = f.value
+	}
+
+	data := entryData(keys, ts, msg, severity(level), nil, nil)
+	maps.Copy(add, data)
+	payload, err := json.Marshal(add)
+	if err != nil {
+		for k, v := range add {
+			add[k] = fmt.Sprint(v)
+		}
+		payload, _ = json.Marshal(add)
+	}
+	return string(payload)
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodingText(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.SetTimeFormat(time.RFC3339, true)
+	l.With(map[string]any{"user": "jane doe"}).Info("hello")
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	ts, _, ok := strings.Cut(line, " ")
+	if _, err := time.Parse(time.RFC3339, ts); err != nil || !strings.HasSuffix(ts, "Z") {
+		t.Error("TestEncodingText:: expected utc rfc3339 timestamp", line)
+	}
+	if !ok || !strings.HasSuffix(line, ` INFO: hello user="jane doe"`) {
+		t.Error("TestEncodingText:: unexpected line", line)
+	}
+}
+
+func TestEncodingLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.SetEncoding(GetEncoding("logfmt"))
+	l.SetTimeFormat(time.RFC3339, true)
+	l.WithPrefix("[db]").With(map[string]any{"rows": 3}).Warn("slow query")
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if !strings.HasPrefix(line, "time=") || !strings.HasSuffix(line, ` level=warn msg="[db] slow query" rows=3`) {
+		t.Error("TestEncodingLogfmt:: unexpected line", line)
+	}
+
+	buf.Reset()
+	l.Err(errors.New("broken pipe"), "write")
+	line = strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(line, "\n") || !strings.HasSuffix(line, ` level=error msg=write error="broken pipe"`) {
+		t.Error("TestEncodingLogfmt:: error should be a field", line)
+	}
+}
+
+func TestEncodingJson(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.SetEncoding(EncodingJson)
+	l.SetKeys(Keys{Message: "msg", Severity: "level"})
+	l.With(map[string]any{"msg": "overwritten", "count": 2}).Err(errors.New("broken"), "failed")
+
+	var data map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatal("TestEncodingJson:: invalid json", buf.String(), err)
+	}
+	if data["msg"] != "failed" || data["level"] != "ERROR" || data["error"] != "broken" || data["count"] != 2.0 {
+		t.Error("TestEncodingJson:: unexpected data", data)
+	}
+	if _, ok := data["timestamp"]; !ok {
+		t.Error("TestEncodingJson:: timestamp key should be kept", data)
+	}
+}
+
+func TestEncodingJsonSpecial(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLError)
+	l.SetEncoding(EncodingJson)
+	SetDefault(l)
+
+	Newline()
+	Time()
+	Plain("plain")
+	PlainTs("plain ts")
+	Flags(true)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatal("TestEncodingJsonSpecial:: expected 3 lines", lines)
+	}
+	for i, want := range []string{"plain", "plain ts", "Flags"} {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &m); err != nil || m["message"] != want {
+			t.Error("TestEncodingJsonSpecial:: unexpected line", lines[i], err)
+		}
+		if i == 2 && m["GOMAXPROCS"] == nil {
+			t.Error("TestEncodingJsonSpecial:: expected flags as fields", lines[i])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"strconv"
+)
+
+// Framing defines how entries are delimited on the wire, so one connection
+// can carry many entries. Datagram protocols send one entry per datagram
+// without framing.
+type Framing int
+
+const (
+	// FramingNewline terminates every entry with '\n' (NDJSON).
+	FramingNewline Framing = iota
+	// FramingOctetCount prefixes every entry with its length and a space
+	// (RFC 6587 octet counting).
+	FramingOctetCount
+	// FramingNull terminates every entry with a null byte.
+	FramingNull
+	// FramingNone writes entries as they are.
+	FramingNone
+)
+
+func GetFraming(framing string) Framing {
+	switch framing {
+	case "octet", "octet-counting", "rfc6587":
+		return FramingOctetCount
+	case "null", "nul":
+		return FramingNull
+	case "none", "raw":
+		return FramingNone
+	}
+	return FramingNewline
+}
+
+// frame appends entry in the framing format to buf.
+func (f Framing) frame(buf, entry []byte) []byte {
+	switch f {
+	case FramingOctetCount:
+		buf = strconv.AppendInt(buf, int64(len(entry)), 10)
+		buf = append(buf, ' ')
+		return append(buf, entry...)
+	case FramingNull:
+		buf = append(buf, entry...)
+		return append(buf, 0)
+	case FramingNone:
+		return append(buf, entry...)
+	}
+	buf = append(buf, entry...)
+	return append(buf, '\n')
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFraming(t *testing.T) {
+	tests := []struct {
+		framing Framing
+		want    string
+	}{
+		{FramingNewline, "{}\n{}\n"},
+		{FramingOctetCount, "2 {}2 {}"},
+		{FramingNull, "{}\x00{}\x00"},
+		{FramingNone, "{}{}"},
+	}
+	for _, test := range tests {
+		var buf []byte
+		buf = test.framing.frame(buf, []byte("{}"))
+		buf = test.framing.frame(buf, []byte("{}"))
+		if string(buf) != test.want {
+			t.Errorf("TestFraming:: framing %d: want %q, got %q", test.framing, test.want, buf)
+		}
+	}
+}
+
+func TestRLogNewlineFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	rlog := NewRLog("127.0.0.1", ln.Addr().(*net.TCPAddr).Port, "info", "", true)
+	rlog.LogString("first")
+	rlog.LogString("second")
+	rlog.Close()
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Error("TestRLogNewlineFraming:: want", want, "got", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("TestRLogNewlineFraming:: missing", want)
+		}
+	}
+}
+
+func TestRLogDatagramUnframed(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	rlog := NewRLogExt("127.0.0.1", conn.LocalAddr().(*net.UDPAddr).Port, "info", "", true, Udp, 1)
+	rlog.Framing = FramingOctetCount
+	rlog.LogString("first")
+	rlog.LogString("second")
+	rlog.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	for _, want := range []string{"first", "second"} {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal("TestRLogDatagramUnframed:: missing", want, err)
+		}
+		if string(buf[:n]) != want {
+			t.Errorf("TestRLogDatagramUnframed:: want %q, got %q", want, buf[:n])
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package httplog ships RLog entries to http endpoints like Loki, the
+// Elasticsearch bulk api or generic webhooks. Batching, spilling and backoff
+// are handled by the RLog queue, auth by the network.Client.
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+	"github.com/nice-pink/goutil/pkg/network"
+)
+
+// Encoder builds the request body for a batch of json entries.
+type Encoder interface {
+	ContentType() string
+	Encode(batch [][]byte) ([]byte, error)
+}
+
+// Transport posts batches to an http endpoint. It implements log.Transport.
+type Transport struct {
+	client       *network.Client
+	url          string
+	encoder      Encoder
+	headers      network.Headers
+	authRequired bool
+}
+
+// NewTransport returns a transport posting to url. If authRequired is set the
+// bearer token, basic auth or auth function of client is used. Requests are
+// never retried by the client, also if it has a retry policy, since the RLog
+// retries with backoff and spills.
+func NewTransport(client *network.Client, url string, encoder Encoder, authRequired bool) *Transport {
+	if client == nil {
+		client = network.NewClient(nil, "", "", nil, false)
+	}
+	return &Transport{
+		client:       client,
+		url:          url,
+		encoder:      encoder,
+		headers:      network.Headers{"Content-Type": encoder.ContentType()},
+		authRequired: authRequired,
+	}
+}
+
+// Use sets the transport as destination of rlog.
+func (t *Transport) Use(rlog *log.RLog) {
+	rlog.Transport = t
+	rlog.Framing = log.FramingNone
+}
+
+func (t *Transport) Send(batch [][]byte) error {
+	body, err := t.encoder.Encode(batch)
+	if err != nil {
+		return err
+	}
+
+	ctx := network.WithRetryPolicy(context.Background(), network.NoRetryPolicy())
+	resp, err := t.client.RequestCtx(ctx, http.MethodPost, t.url, bytes.NewReader(body), t.headers, t.authRequired)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.New("bad status: " + resp.Status + " " + string(msg))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (t *Transport) Close() error {
+	return nil
+}
+
+// json array
+
+// JsonArray posts the entries as json array, e.g. for webhooks.
+type JsonArray struct{}
+
+func (JsonArray) ContentType() string {
+	return "application/json"
+}
+
+func (JsonArray) Encode(batch [][]byte) ([]byte, error) {
+	body := []byte{'['}
+	body = append(body, bytes.Join(batch, []byte{','})...)
+	return append(body, ']'), nil
+}
+
+// elasticsearch
+
+// ElasticBulk posts the entries as index actions to the _bulk api.
+type ElasticBulk struct {
+	Index string // empty to use the index of the url
+}
+
+func (ElasticBulk) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (e ElasticBulk) Encode(batch [][]byte) ([]byte, error) {
+	action := []byte(`{"index":{}}`)
+	if e.Index != "" {
+		action, _ = json.Marshal(map[string]any{"index": map[string]string{"_index": e.Index}})
+	}
+
+	var body []byte
+	for _, entry := range batch {
+		body = append(body, action...)
+		body = append(body, '\n')
+		body = append(body, entry...)
+		body = append(body, '\n')
+	}
+	return body, nil
+}
+
+// loki
+
+// Loki posts the entries as one stream to the loki push api. The entry is
+// used as log line, its timestamp is parsed from TimestampKey.
+type Loki struct {
+	Labels       map[string]string
+	TimestampKey string
+	TimeFormat   string
+	Location     *time.Location // of timestamps without zone, local if nil
+}
+
+// NewLoki returns an encoder matching the keys and time format of rlog. The
+// CommonData of rlog are used as stream labels, masked by the redactor.
+func NewLoki(rlog *log.RLog) *Loki {
+	labels := map[string]string{}
+	for k, v := range log.GetRedactor().Data(rlog.CommonData) {
+		labels[k] = fmt.Sprint(v)
+	}
+	loki := &Loki{Labels: labels, TimestampKey: rlog.Keys.Timestamp, TimeFormat: rlog.TimeFormat}
+	if rlog.IsUtc {
+		loki.Location = time.UTC
+	}
+	return loki
+}
+
+func (l *Loki) ContentType() string {
+	return "application/json"
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (l *Loki) Encode(batch [][]byte) ([]byte, error) {
+	stream := lokiStream{Stream: l.Labels, Values: make([][2]string, 0, len(batch))}
+	if stream.Stream == nil {
+		stream.Stream = map[string]string{}
+	}
+	for _, entry := range batch {
+		ts := strconv.FormatInt(l.timestamp(entry).UnixNano(), 10)
+		stream.Values = append(stream.Values, [2]string{ts, string(entry)})
+	}
+	return json.Marshal(map[string]any{"streams": []lokiStream{stream}})
+}
+
+func (l *Loki) timestamp(entry []byte) time.Time {
+	if l.TimestampKey == "" {
+		return time.Now()
+	}
+	var data map[string]any
+	if json.Unmarshal(entry, &data) != nil {
+		return time.Now()
+	}
+	value, ok := data[l.TimestampKey].(string)
+	if !ok {
+		return time.Now()
+	}
+	location := l.Location
+	if location == nil {
+		location = time.Local
+	}
+	ts, err := time.ParseInLocation(l.TimeFormat, value, location)
+	if err != nil {
+		return time.Now()
+	}
+	return ts
+}
@@ -0,0 +1,157 @@
+package httplog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+	"github.com/nice-pink/goutil/pkg/network"
+)
+
+type request struct {
+	auth        string
+	contentType string
+	body        string
+}
+
+func newServer(t *testing.T, status int) (*httptest.Server, chan request) {
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{auth: r.Header.Get("Authorization"), contentType: r.Header.Get("Content-Type"), body: string(body)}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func newRLog(t *testing.T) *log.RLog {
+	rlog := log.NewRLog("", 0, "info", "", true)
+	rlog.UpdateCommonData(map[string]interface{}{"app": "test"})
+	t.Cleanup(rlog.Close)
+	return rlog
+}
+
+func receive(t *testing.T, requests chan request) request {
+	select {
+	case r := <-requests:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("no request received")
+	}
+	return request{}
+}
+
+func TestJsonArray(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK)
+	client := network.NewClient(nil, "", "", func() (string, error) { return "token", nil }, false)
+
+	rlog := newRLog(t)
+	NewTransport(client, server.URL, JsonArray{}, true).Use(rlog)
+	rlog.Info("one")
+	rlog.Info("two")
+	rlog.Flush()
+
+	r := receive(t, requests)
+	var entries []map[string]any
+	if err := json.Unmarshal([]byte(r.body), &entries); err != nil {
+		t.Fatal("TestJsonArray:: invalid body", r.body)
+	}
+	if len(entries) != 2 || entries[1]["message"] != "two" || entries[0]["app"] != "test" {
+		t.Error("TestJsonArray:: unexpected entries", entries)
+	}
+	if r.auth != "Bearer token" || r.contentType != "application/json" {
+		t.Error("TestJsonArray:: unexpected headers", r.auth, r.contentType)
+	}
+	if s := rlog.Stats(); s.Sent != 2 {
+		t.Error("TestJsonArray:: unexpected stats", s)
+	}
+}
+
+func TestElasticBulk(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK)
+	client := network.NewClient(nil, "", network.GetBasicAuth("user", "pass"), nil, false)
+
+	rlog := newRLog(t)
+	NewTransport(client, server.URL+"/_bulk", ElasticBulk{Index: "logs"}, true).Use(rlog)
+	rlog.Warn("one")
+	rlog.Flush()
+
+	r := receive(t, requests)
+	lines := strings.Split(r.body, "\n")
+	if len(lines) != 3 || lines[0] != `{"index":{"_index":"logs"}}` || !strings.Contains(lines[1], `"severity":"WARN"`) || lines[2] != "" {
+		t.Error("TestElasticBulk:: unexpected body", r.body)
+	}
+	if !strings.HasPrefix(r.auth, "Basic ") || r.contentType != "application/x-ndjson" {
+		t.Error("TestElasticBulk:: unexpected headers", r.auth, r.contentType)
+	}
+}
+
+func TestLoki(t *testing.T) {
+	server, requests := newServer(t, http.StatusNoContent)
+
+	rlog := newRLog(t)
+	NewTransport(nil, server.URL+"/loki/api/v1/push", NewLoki(rlog), false).Use(rlog)
+	before := time.Now().Add(-time.Second)
+	rlog.Error("one")
+	rlog.Flush()
+
+	r := receive(t, requests)
+	var push struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(r.body), &push); err != nil || len(push.Streams) != 1 {
+		t.Fatal("TestLoki:: invalid body", r.body)
+	}
+	stream := push.Streams[0]
+	if stream.Stream["app"] != "test" || len(stream.Values) != 1 || !strings.Contains(stream.Values[0][1], `"message":"one"`) {
+		t.Error("TestLoki:: unexpected stream", stream)
+	}
+	ts, err := strconv.ParseInt(stream.Values[0][0], 10, 64)
+	if err != nil || ts < before.UnixNano() || ts > time.Now().UnixNano() {
+		t.Error("TestLoki:: unexpected timestamp", stream.Values[0][0])
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	server, requests := newServer(t, http.StatusInternalServerError)
+
+	rlog := newRLog(t)
+	NewTransport(nil, server.URL, JsonArray{}, false).Use(rlog)
+	rlog.Info("one")
+	rlog.Flush()
+
+	receive(t, requests)
+	if s := rlog.Stats(); s.Failed != 1 || s.Sent != 0 {
+		t.Error("TestTransportError:: unexpected stats", s)
+	}
+}
+
+func TestTransportNoClientRetry(t *testing.T) {
+	server, requests := newServer(t, http.StatusServiceUnavailable)
+
+	// retries of the client would stack on the backoff of the rlog
+	client := network.NewClient(nil, "", "", nil, false)
+	policy := network.DefaultRetryPolicy()
+	policy.RetryNonIdempotent = true
+	client.SetRetryPolicy(policy)
+
+	rlog := newRLog(t)
+	NewTransport(client, server.URL, JsonArray{}, false).Use(rlog)
+	rlog.Info("one")
+	rlog.Flush()
+
+	receive(t, requests)
+	if len(requests) != 0 {
+		t.Error("TestTransportNoClientRetry:: request should be sent once", len(requests)+1)
+	}
+	if s := rlog.Stats(); s.Failed != 1 {
+		t.Error("TestTransportNoClientRetry:: unexpected stats", s)
+	}
+}
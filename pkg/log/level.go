@@ -0,0 +1,224 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nice-pink/goutil/pkg/env"
+)
+
+// LevelEnv sets the level of the default logger at startup, LOG_LEVEL_<NAME>
+// the level of the named logger NAME, e.g. LOG_LEVEL_DB=debug.
+const LevelEnv = "LOG_LEVEL"
+
+// Leveled is implemented by *Logger and *RLog.
+type Leveled interface {
+	Level() LogLevel
+	SetLevel(level LogLevel)
+}
+
+// DefaultName is the registry name of the default logger.
+const DefaultName = "default"
+
+// registry holds loggers added by Register and by Named separately, so
+// neither replaces the other. Both follow level changes of their name.
+var registry = struct {
+	mu      sync.Mutex
+	loggers map[string]Leveled
+	named   map[string]*Logger
+	saved   map[string]LogLevel
+}{loggers: map[string]Leveled{}, named: map[string]*Logger{}}
+
+func init() {
+	if level := env.GetEnvString(LevelEnv, ""); level != "" {
+		Default().SetLevel(GetLogLevel(level))
+	}
+}
+
+// LevelFromEnv returns the level of the env var key or def if unset or
+// invalid.
+func LevelFromEnv(key string, def LogLevel) LogLevel {
+	level, err := ParseLogLevel(env.GetEnvString(key, def.String()))
+	if err != nil {
+		return def
+	}
+	return level
+}
+
+// Register makes the level of l adjustable at runtime under name.
+func Register(name string, l Leveled) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.loggers[name] = l
+}
+
+// Named returns the logger named name or creates a copy of the default
+// logger with prefix [name]. The level is read from LOG_LEVEL_<NAME>.
+// Loggers added by Register under the same name are kept.
+func Named(name string) *Logger {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if l, ok := registry.named[name]; ok {
+		return l
+	}
+	l := Default().WithPrefix("[" + name + "]")
+	l.SetLevel(LevelFromEnv(LevelEnv+"_"+envName(name), l.Level()))
+	registry.named[name] = l
+	return l
+}
+
+// FlushAll flushes the default logger and all registered loggers, e.g.
+// before exit.
+func FlushAll() {
+	Default().Flush()
+
+	registry.mu.Lock()
+	loggers := slices.Collect(maps.Values(registry.loggers))
+	for _, l := range registry.named {
+		loggers = append(loggers, l)
+	}
+	registry.mu.Unlock()
+	for _, l := range loggers {
+		if f, ok := l.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+}
+
+// SetLevel changes the level of the loggers registered or named as name.
+func SetLevel(name string, level LogLevel) error {
+	loggers := lookup(name)
+	if len(loggers) == 0 {
+		return errors.New("unknown logger: " + name)
+	}
+	for _, l := range loggers {
+		l.SetLevel(level)
+	}
+	return nil
+}
+
+// Levels returns the levels of all registered and named loggers and the
+// default logger. The registered logger wins if a name is used by both.
+func Levels() map[string]LogLevel {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	levels := map[string]LogLevel{DefaultName: Default().Level()}
+	for name, l := range registry.named {
+		levels[name] = l.Level()
+	}
+	for name, l := range registry.loggers {
+		levels[name] = l.Level()
+	}
+	return levels
+}
+
+func lookup(name string) []Leveled {
+	if name == "" || name == DefaultName {
+		return []Leveled{Default()}
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	loggers := []Leveled{}
+	if l, ok := registry.loggers[name]; ok {
+		loggers = append(loggers, l)
+	}
+	if l, ok := registry.named[name]; ok {
+		loggers = append(loggers, l)
+	}
+	return loggers
+}
+
+// raise sets all loggers to level and saves the previous levels for restore.
+func raise(level LogLevel) {
+	levels := Levels()
+
+	registry.mu.Lock()
+	if registry.saved == nil {
+		registry.saved = levels
+	}
+	registry.mu.Unlock()
+
+	for name := range levels {
+		SetLevel(name, level)
+	}
+}
+
+// restore resets the levels saved by raise.
+func restore() {
+	registry.mu.Lock()
+	saved := registry.saved
+	registry.saved = nil
+	registry.mu.Unlock()
+
+	for name, level := range saved {
+		SetLevel(name, level)
+	}
+}
+
+func envName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name))
+}
+
+// http
+
+type levelRequest struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// LevelHandler serves the levels of all registered loggers.
+//
+// GET returns {"default":"info","db":"debug"}.
+// PUT or POST sets a level by query (?name=db&level=debug) or json body
+// ({"name":"db","level":"debug"}). An empty name selects the default logger.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			req := levelRequest{Name: r.URL.Query().Get("name"), Level: r.URL.Query().Get("level")}
+			if req.Level == "" {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			level, err := ParseLogLevel(req.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := SetLevel(req.Name, level); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			Default().Info("Set log level of", nameOrDefault(req.Name), "to", level)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		levels := map[string]string{}
+		for name, level := range Levels() {
+			levels[name] = level.String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levels)
+	})
+}
+
+func nameOrDefault(name string) string {
+	if name == "" {
+		return DefaultName
+	}
+	return name
+}
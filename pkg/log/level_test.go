@@ -0,0 +1,90 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNamedLevels(t *testing.T) {
+	t.Setenv("LOG_LEVEL_TEST_DB", "error")
+
+	db := Named("test-db")
+	if db != Named("test-db") {
+		t.Error("TestNamedLevels:: named logger should be reused")
+	}
+	if db.Level() != LLError {
+		t.Error("TestNamedLevels:: level should be read from env, got", db.Level())
+	}
+
+	rlog := NewRLog("", 0, "info", "", true)
+	Register("test-rlog", rlog)
+	if err := SetLevel("test-rlog", LLWarn); err != nil || rlog.Level() != LLWarn {
+		t.Error("TestNamedLevels:: rlog level not set", err, rlog.Level())
+	}
+	if err := SetLevel("unknown", LLWarn); err == nil {
+		t.Error("TestNamedLevels:: unknown logger should fail")
+	}
+	if levels := Levels(); levels["test-db"] != LLError || levels["test-rlog"] != LLWarn {
+		t.Error("TestNamedLevels:: unexpected levels", levels)
+	}
+}
+
+func TestNamedKeepsRegistered(t *testing.T) {
+	rlog := NewRLog("", 0, "info", "", true)
+	Register("test-shared", rlog)
+	named := Named("test-shared")
+
+	if lookup("test-shared")[0] != rlog {
+		t.Error("TestNamedKeepsRegistered:: registered rlog should be kept")
+	}
+	if err := SetLevel("test-shared", LLError); err != nil || rlog.Level() != LLError || named.Level() != LLError {
+		t.Error("TestNamedKeepsRegistered:: both loggers should follow the level", err, rlog.Level(), named.Level())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+	SetDefault(NewLogger(&bytes.Buffer{}, LLInfo))
+	Named("test-handler")
+
+	handler := LevelHandler()
+
+	req := httptest.NewRequest(http.MethodPut, "/loglevel?name=test-handler&level=debug", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || Named("test-handler").Level() != LLDebug {
+		t.Error("TestLevelHandler:: put by query failed", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"warn"}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || Default().Level() != LLWarn {
+		t.Error("TestLevelHandler:: put by body failed", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/loglevel?level=loud", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Error("TestLevelHandler:: invalid level should fail", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+	levels := map[string]string{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &levels); err != nil || levels[DefaultName] != "warn" || levels["test-handler"] != "debug" {
+		t.Error("TestLevelHandler:: unexpected levels", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/loglevel", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PUT, POST" {
+		t.Error("TestLevelHandler:: unexpected allowed methods", rec.Code, rec.Header())
+	}
+}
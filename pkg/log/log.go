@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"runtime"
-	"strings"
 	"time"
 )
 
@@ -22,121 +21,108 @@ const (
 )
 
 func Newline() {
-	fmt.Println()
+	Default().Newline()
 }
 
 func Verbose(logs ...any) {
-	params := append([]any{time.Now().Format(time.DateTime) + " VERBOSE:"}, logs...)
-	fmt.Println(params...)
+	Default().Verbose(logs...)
 }
 
 func Debug(logs ...any) {
-	params := append([]any{time.Now().Format(time.DateTime) + " DEBUG:"}, logs...)
-	fmt.Println(params...)
+	Default().Debug(logs...)
 }
 
 func Info(logs ...any) {
-	params := append([]any{time.Now().Format(time.DateTime) + " INFO:"}, logs...)
-	fmt.Println(params...)
+	Default().Info(logs...)
 }
 
 func Warn(logs ...any) {
-	var params []any
-	if strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true" {
-		params = append([]any{time.Now().Format(time.DateTime) + " WARNING:"}, logs...)
-	} else {
-		params = append([]any{Yellow + time.Now().Format(time.DateTime) + " WARNING:" + Reset}, logs...)
-	}
-	fmt.Println(params...)
+	Default().Warn(logs...)
 }
 
 func Warning(logs ...any) {
-	Warn(logs...)
+	Default().Warn(logs...)
 }
 
 func Error(logs ...any) {
-	var params []any
-	if strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true" {
-		params = append([]any{time.Now().Format(time.DateTime) + " ERROR:"}, logs...)
-	} else {
-		params = append([]any{Red + time.Now().Format(time.DateTime) + " ERROR:" + Reset}, logs...)
-	}
-	fmt.Println(params...)
+	Default().Error(logs...)
 }
 
 func Err(err error, logs ...any) {
-	var params []any
-	if strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true" {
-		params = append([]any{time.Now().Format(time.DateTime) + " ERROR:"}, logs...)
-	} else {
-		params = append([]any{Red + time.Now().Format(time.DateTime) + " ERROR:" + Reset}, logs...)
-	}
-	fmt.Println(params...)
-	fmt.Println(err)
+	Default().Err(err, logs...)
 }
 
 func Critical(logs ...any) {
-	var params []any
-	if strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true" {
-		params = append([]any{time.Now().Format(time.DateTime) + " ERROR:"}, logs...)
-	} else {
-		params = append([]any{Magenta + time.Now().Format(time.DateTime) + " ERROR:" + Reset}, logs...)
-	}
-	fmt.Println(params...)
+	Default().Critical(logs...)
 }
 
 // special
 
 func PlainTs(logs ...any) {
-	params := append([]any{time.Now().Format(time.DateTime) + ":"}, logs...)
-	fmt.Println(params...)
+	Default().PlainTs(logs...)
 }
 
 func Plain(logs ...any) {
-	fmt.Println(logs...)
+	Default().Plain(logs...)
 }
 
 func Notify(logs ...any) {
-	var params []any
-	if strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true" {
-		params = append([]any{time.Now().Format(time.DateTime) + " INFO:"}, logs...)
-	} else {
-		params = append([]any{Blue + time.Now().Format(time.DateTime) + " INFO:" + Reset}, logs...)
-	}
-	fmt.Println(params...)
+	Default().Notify(logs...)
 }
 
 func Success(logs ...any) {
-	var params []any
-	if strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true" {
-		params = append([]any{time.Now().Format(time.DateTime) + " INFO:"}, logs...)
-	} else {
-		params = append([]any{Green + time.Now().Format(time.DateTime) + " INFO:" + Reset}, logs...)
-	}
-	fmt.Println(params...)
+	Default().Success(logs...)
 }
 
 func Time() {
-	fmt.Println(time.Now().Format(time.DateTime))
+	Default().Time()
 }
 
+// Flags prints all flags. Values of flags named like a redact key, e.g.
+// -db-password, are masked. DefaultRedactKeys are used if no redactor is
+// set. In logfmt and json encoding one info entry with the flags as fields
+// is printed.
 func Flags(goEnvVars bool) {
-	// flags
-	fmt.Println(Blue + "Flags:")
+	r := GetRedactor()
+	if r == nil {
+		r = NewRedactor(DefaultRedactKeys)
+	}
+
+	fields := []field{}
 	flag.VisitAll(func(f *flag.Flag) {
-		fmt.Printf(Blue+"-%s: %s\n"+Reset, f.Name, f.Value)
+		value := f.Value.String()
+		if r.isKey(f.Name) && value != "" {
+			value = Mask
+		}
+		fields = append(fields, field{key: f.Name, value: r.String(value)})
 	})
 
+	l := Default()
+	if l.Encoding() != EncodingText {
+		if goEnvVars {
+			fields = append(fields, field{key: "GOMAXPROCS", value: runtime.GOMAXPROCS(0)}, field{key: "GOMEMLIMIT", value: os.Getenv("GOMEMLIMIT")})
+		}
+		l.print(time.Now(), LLInfo, "INFO", "", []any{"Flags"}, fields)
+		return
+	}
+
+	// flags
+	out := l.Output()
+	fmt.Fprintln(out, Blue+"Flags:")
+	for _, f := range fields {
+		fmt.Fprintf(out, Blue+"-%s: %s\n"+Reset, f.key, f.value)
+	}
+
 	// go env vars
 	if goEnvVars {
-		fmt.Println()
-		fmt.Println(Blue+"GOMAXPROCS:", runtime.GOMAXPROCS(0), Reset)
-		fmt.Println(Blue+"GOMEMLIMIT:", os.Getenv("GOMEMLIMIT"), Reset)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, Blue+"GOMAXPROCS:", runtime.GOMAXPROCS(0), Reset)
+		fmt.Fprintln(out, Blue+"GOMEMLIMIT:", os.Getenv("GOMEMLIMIT"), Reset)
 	}
 }
 
 func StartApp() {
-	fmt.Println("*** Start")
+	Default().Plain("*** Start")
 	Flags(true)
 	Newline()
 }
@@ -0,0 +1,380 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger writes leveled log lines to an io.Writer. Lines below the minimum
+// log level are dropped. Prefix and fields are added to every line.
+type Logger struct {
+	mu      sync.Mutex
+	out     io.Writer
+	level   LogLevel
+	prefix  string
+	fields  map[string]any
+	sampler *Sampler
+
+	// encoding
+	encoding   Encoding
+	timeFormat string
+	isUtc      bool
+	keys       Keys
+
+	// enrichment
+	caller bool
+	stack  bool
+}
+
+// initialized before any init function, those may configure the logger
+var defaultLogger = func() *atomic.Pointer[Logger] {
+	p := &atomic.Pointer[Logger]{}
+	p.Store(NewLogger(os.Stdout, LLVerbose))
+	return p
+}()
+
+// NewLogger returns a logger writing to out (stdout if nil) which drops all
+// lines below level.
+func NewLogger(out io.Writer, level LogLevel) *Logger {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &Logger{out: out, level: level, timeFormat: time.DateTime, keys: DefaultKeys()}
+}
+
+// Default returns the logger used by the package level functions.
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefault replaces the logger used by the package level functions.
+func SetDefault(l *Logger) {
+	if l == nil {
+		return
+	}
+	defaultLogger.Store(l)
+}
+
+// config
+
+func (l *Logger) SetLevel(level LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+func (l *Logger) Level() LogLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level >= l.Level()
+}
+
+func (l *Logger) SetOutput(out io.Writer) {
+	if out == nil {
+		out = os.Stdout
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = out
+}
+
+func (l *Logger) Output() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.out
+}
+
+func (l *Logger) SetPrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prefix = prefix
+}
+
+// SetSampler drops repeated lines, see Sampler. Copies created by With share
+// the sampler.
+func (l *Logger) SetSampler(sampler *Sampler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sampler = sampler
+}
+
+// Flush prints the summaries of lines suppressed by the sampler.
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	sampler := l.sampler
+	l.mu.Unlock()
+	if sampler != nil {
+		l.printSuppressed(sampler.Flush())
+	}
+}
+
+// With returns a copy of the logger with fields added to every line.
+func (l *Logger) With(fields map[string]any) *Logger {
+	c := l.clone()
+	if c.fields == nil {
+		c.fields = map[string]any{}
+	}
+	maps.Copy(c.fields, fields)
+	return c
+}
+
+// WithPrefix returns a copy of the logger using prefix.
+func (l *Logger) WithPrefix(prefix string) *Logger {
+	c := l.clone()
+	c.prefix = prefix
+	return c
+}
+
+// log
+
+// Newline prints an empty line in text encoding only.
+func (l *Logger) Newline() {
+	if l.Encoding() != EncodingText {
+		return
+	}
+	l.write([]byte("\n"))
+}
+
+func (l *Logger) Verbose(logs ...any) {
+	l.log(LLVerbose, "VERBOSE", "", logs)
+}
+
+func (l *Logger) Debug(logs ...any) {
+	l.log(LLDebug, "DEBUG", "", logs)
+}
+
+func (l *Logger) Info(logs ...any) {
+	l.log(LLInfo, "INFO", "", logs)
+}
+
+func (l *Logger) Warn(logs ...any) {
+	l.log(LLWarn, "WARNING", Yellow, logs)
+}
+
+func (l *Logger) Warning(logs ...any) {
+	l.Warn(logs...)
+}
+
+func (l *Logger) Error(logs ...any) {
+	l.log(LLError, "ERROR", Red, logs)
+}
+
+func (l *Logger) Err(err error, logs ...any) {
+	if !l.Enabled(LLError) || !l.sample(LLError, append([]any{err}, logs...)) {
+		return
+	}
+	if l.Encoding() != EncodingText {
+		l.print(time.Now(), LLError, "ERROR", Red, logs, errorFields(err))
+		return
+	}
+	l.print(time.Now(), LLError, "ERROR", Red, logs, nil)
+
+	// error and causes on the following lines
+	lines := []string{fmt.Sprint(err)}
+	for i, c := range errorCauses(err) {
+		lines = append(lines, "\t"+CauseKey+"."+strconv.Itoa(i+1)+": "+c.Error())
+	}
+	l.write([]byte(GetRedactor().String(strings.Join(lines, "\n")) + "\n"))
+}
+
+func (l *Logger) Critical(logs ...any) {
+	l.log(LLCritical, "ERROR", Magenta, logs)
+}
+
+func (l *Logger) Notify(logs ...any) {
+	l.log(LLInfo, "INFO", Blue, logs)
+}
+
+func (l *Logger) Success(logs ...any) {
+	l.log(LLInfo, "INFO", Green, logs)
+}
+
+// special
+
+// Plain, PlainTs and Time print regardless of the level. In logfmt and json
+// encoding Plain and PlainTs print an info entry and Time prints nothing.
+
+func (l *Logger) PlainTs(logs ...any) {
+	if l.Encoding() != EncodingText {
+		l.print(time.Now(), LLInfo, "INFO", "", logs, nil)
+		return
+	}
+	l.write([]byte(GetRedactor().String(fmt.Sprintln(append([]any{time.Now().Format(time.DateTime) + ":"}, logs...)...))))
+}
+
+func (l *Logger) Plain(logs ...any) {
+	if l.Encoding() != EncodingText {
+		l.print(time.Now(), LLInfo, "INFO", "", logs, nil)
+		return
+	}
+	l.write([]byte(GetRedactor().String(fmt.Sprintln(logs...))))
+}
+
+func (l *Logger) Time() {
+	if l.Encoding() != EncodingText {
+		return
+	}
+	l.write([]byte(fmt.Sprintln(time.Now().Format(time.DateTime))))
+}
+
+// intern
+
+func (l *Logger) clone() *Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &Logger{
+		out:        l.out,
+		level:      l.level,
+		prefix:     l.prefix,
+		fields:     maps.Clone(l.fields),
+		sampler:    l.sampler,
+		encoding:   l.encoding,
+		timeFormat: l.timeFormat,
+		isUtc:      l.isUtc,
+		keys:       l.keys,
+		caller:     l.caller,
+		stack:      l.stack,
+	}
+}
+
+func (l *Logger) log(level LogLevel, label, color string, logs []any) {
+	if !l.Enabled(level) {
+		return
+	}
+	if !l.sample(level, logs) {
+		return
+	}
+	l.print(time.Now(), level, label, color, logs, nil)
+}
+
+// sample reports if the line passes the sampler. Summaries of suppressed
+// lines are printed first.
+func (l *Logger) sample(level LogLevel, logs []any) bool {
+	l.mu.Lock()
+	sampler := l.sampler
+	l.mu.Unlock()
+	if sampler == nil {
+		return true
+	}
+
+	ok, summaries := sampler.sample(level, getMsg(logs...), l.printSuppressed)
+	l.printSuppressed(summaries)
+	return ok
+}
+
+func (l *Logger) printSuppressed(summaries []Suppressed) {
+	for _, s := range summaries {
+		l.print(time.Now(), s.Level, levelLabel(s.Level), levelColor(s.Level), []any{s.String()}, nil)
+	}
+}
+
+// print renders one line in the encoding of the logger. extra is appended
+// after the logger fields, caller and stack are added last if enabled.
+func (l *Logger) print(ts time.Time, level LogLevel, label, color string, logs []any, extra []field) {
+	l.mu.Lock()
+	encoding, timeFormat, keys, prefix := l.encoding, l.timeFormat, l.keys, l.prefix
+	if l.isUtc {
+		ts = ts.UTC()
+	}
+	fields := append(sortedFields(l.fields), extra...)
+	addCaller, addStack := l.caller, l.stack && level >= LLCritical
+	l.mu.Unlock()
+
+	if addCaller {
+		fields = append(fields, field{key: CallerKey, value: caller()})
+	}
+	trace := ""
+	if addStack {
+		trace = stack()
+		if encoding != EncodingText {
+			fields = append(fields, field{key: StackKey, value: trace})
+		}
+	}
+
+	// redact before encoding
+	msg := getMsg(logs...)
+	if r := GetRedactor(); r != nil {
+		msg = r.String(msg)
+		fields = r.fields(fields)
+	}
+
+	var line string
+	switch encoding {
+	case EncodingLogfmt:
+		line = encodeLogfmt(ts.Format(timeFormat), level, prefix, msg, fields)
+	case EncodingJson:
+		line = encodeJson(keys, ts.Format(timeFormat), level, prefix, msg, fields)
+	default:
+		head := ts.Format(timeFormat) + " " + label + ":"
+		if color != "" && !ignoreColor() {
+			head = color + head + Reset
+		}
+		params := []any{head}
+		if prefix != "" {
+			params = append(params, prefix)
+		}
+		params = append(params, msg)
+		line = strings.TrimSuffix(fmt.Sprintln(params...), "\n")
+		if len(fields) > 0 {
+			line += " " + renderFields(fields)
+		}
+		if trace != "" {
+			line += "\n" + trace
+		}
+	}
+
+	consoleEntries.add(level)
+	l.write([]byte(line + "\n"))
+}
+
+func (l *Logger) write(line []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line)
+}
+
+func levelLabel(level LogLevel) string {
+	switch level {
+	case LLVerbose:
+		return "VERBOSE"
+	case LLDebug:
+		return "DEBUG"
+	case LLInfo:
+		return "INFO"
+	case LLWarn:
+		return "WARNING"
+	}
+	return "ERROR"
+}
+
+func levelColor(level LogLevel) string {
+	switch level {
+	case LLWarn:
+		return Yellow
+	case LLError:
+		return Red
+	case LLCritical:
+		return Magenta
+	}
+	return ""
+}
+
+func ignoreColor() bool {
+	return strings.ToLower(os.Getenv("LOG_IGNORE_COLOR")) == "true"
+}
+
+// formatFields renders fields as key=value pairs sorted by key.
+func formatFields(fields map[string]any) string {
+	return renderFields(sortedFields(fields))
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoggerLevel(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLInfo)
+
+	l.Verbose("verbose")
+	l.Debug("debug")
+	if buf.Len() != 0 {
+		t.Error("TestLoggerLevel:: should drop lines below level", buf.String())
+	}
+
+	l.Info("info", 1)
+	if !strings.HasSuffix(buf.String(), " INFO: info 1\n") {
+		t.Error("TestLoggerLevel:: unexpected info line", buf.String())
+	}
+
+	buf.Reset()
+	l.SetLevel(LLError)
+	l.Warn("warn")
+	l.Err(errors.New("broken"), "error")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], " ERROR: error") || lines[1] != "broken" {
+		t.Error("TestLoggerLevel:: unexpected error lines", lines)
+	}
+}
+
+func TestLoggerPrefixAndFields(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose).WithPrefix("[svc]").With(map[string]any{"b": 2, "a": "x"})
+	l.Info("hello")
+	if !strings.HasSuffix(buf.String(), " INFO: [svc] hello a=x b=2\n") {
+		t.Error("TestLoggerPrefixAndFields:: unexpected line", buf.String())
+	}
+}
+
+func TestDefaultLogger(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	prev := Default()
+	defer SetDefault(prev)
+
+	var buf bytes.Buffer
+	SetDefault(NewLogger(&buf, LLWarn))
+	Debug("debug")
+	Info("info")
+	Warn("warn")
+	Plain("plain")
+	if !strings.Contains(buf.String(), "WARNING: warn\nplain\n") {
+		t.Error("TestDefaultLogger:: unexpected output", buf.String())
+	}
+	if strings.Contains(buf.String(), "info") || strings.Contains(buf.String(), "debug") {
+		t.Error("TestDefaultLogger:: should drop lines below level", buf.String())
+	}
+}
@@ -0,0 +1,194 @@
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+)
+
+// Collector is a fake log endpoint on 127.0.0.1 receiving newline framed
+// entries over tcp or udp. It is closed when the test finishes.
+type Collector struct {
+	protocol log.ConnProtocol
+	ln       net.Listener
+	pc       net.PacketConn
+
+	mu      sync.Mutex
+	conns   []net.Conn
+	closed  bool
+	lines   []string
+	changed chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewCollector starts a collector for protocol log.Tcp or log.Udp.
+func NewCollector(t testing.TB, protocol log.ConnProtocol) *Collector {
+	t.Helper()
+	c := &Collector{protocol: protocol, changed: make(chan struct{})}
+
+	var err error
+	switch protocol {
+	case log.Tcp:
+		c.ln, err = net.Listen("tcp", "127.0.0.1:0")
+		if err == nil {
+			c.wg.Add(1)
+			go c.accept()
+		}
+	case log.Udp:
+		c.pc, err = net.ListenPacket("udp", "127.0.0.1:0")
+		if err == nil {
+			c.wg.Add(1)
+			go c.receive()
+		}
+	default:
+		t.Fatal("logtest: unsupported protocol", protocol)
+	}
+	if err != nil {
+		t.Fatal("logtest: cannot listen", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Addr returns host:port of the collector.
+func (c *Collector) Addr() string {
+	if c.ln != nil {
+		return c.ln.Addr().String()
+	}
+	return c.pc.LocalAddr().String()
+}
+
+func (c *Collector) Host() string {
+	host, _, _ := net.SplitHostPort(c.Addr())
+	return host
+}
+
+func (c *Collector) Port() int {
+	_, port, _ := net.SplitHostPort(c.Addr())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// RLog returns an rlog sending to the collector.
+func (c *Collector) RLog(logLevel string) *log.RLog {
+	return log.NewRLogExt(c.Host(), c.Port(), logLevel, "", true, c.protocol, 3)
+}
+
+// Lines returns all received lines.
+func (c *Collector) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.lines...)
+}
+
+// Entries returns all received lines decoded as json objects. Lines which
+// are no json are skipped.
+func (c *Collector) Entries() []map[string]interface{} {
+	entries := []map[string]interface{}{}
+	for _, line := range c.Lines() {
+		data := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &data); err == nil {
+			entries = append(entries, data)
+		}
+	}
+	return entries
+}
+
+// Wait blocks until n lines were received and returns them. The test fails
+// if they don't arrive within timeout.
+func (c *Collector) Wait(t testing.TB, n int, timeout time.Duration) []string {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		c.mu.Lock()
+		lines := append([]string{}, c.lines...)
+		changed := c.changed
+		c.mu.Unlock()
+		if len(lines) >= n {
+			return lines
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			t.Fatalf("logtest: expected %d lines within %s, got %d: %q", n, timeout, len(lines), lines)
+			return lines
+		}
+	}
+}
+
+// Close stops the collector.
+func (c *Collector) Close() {
+	if c.ln != nil {
+		c.ln.Close()
+	}
+	if c.pc != nil {
+		c.pc.Close()
+	}
+	c.mu.Lock()
+	c.closed = true
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+// intern
+
+func (c *Collector) accept() {
+	defer c.wg.Done()
+	for {
+		conn, err := c.ln.Accept()
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			conn.Close()
+			return
+		}
+		c.conns = append(c.conns, conn)
+		c.wg.Add(1)
+		c.mu.Unlock()
+		go func() {
+			defer c.wg.Done()
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				c.add(scanner.Text())
+			}
+		}()
+	}
+}
+
+func (c *Collector) receive() {
+	defer c.wg.Done()
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := c.pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		for _, line := range bytes.Split(bytes.TrimSuffix(buf[:n], []byte("\n")), []byte("\n")) {
+			c.add(string(line))
+		}
+	}
+}
+
+func (c *Collector) add(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = append(c.lines, line)
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
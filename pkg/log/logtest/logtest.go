@@ -0,0 +1,164 @@
+// Package logtest captures log entries in tests. A Recorder collects entries
+// of the package level functions, a Logger, or an RLog destination. A
+// Collector is a local TCP or UDP endpoint receiving entries of an RLog.
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+)
+
+// Recorder stores entries. It is a log.Sink and an io.Writer accepting json
+// lines of a log.Logger. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	keys    log.Keys
+	entries []log.Entry
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{keys: log.DefaultKeys()}
+}
+
+// Capture replaces the default logger by a logger writing to a new recorder
+// for the duration of the test.
+func Capture(t testing.TB) *Recorder {
+	t.Helper()
+	r := NewRecorder()
+	prev := log.Default()
+	t.Cleanup(func() { log.SetDefault(prev) })
+	log.SetDefault(r.Logger(log.LLVerbose))
+	return r
+}
+
+// Logger returns a logger writing to the recorder.
+func (r *Recorder) Logger(level log.LogLevel) *log.Logger {
+	l := log.NewLogger(r, level)
+	l.SetEncoding(log.EncodingJson)
+	l.SetTimeFormat(time.RFC3339Nano, false)
+	return l
+}
+
+// Log implements log.Sink.
+func (r *Recorder) Log(e log.Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+// Write parses json lines written by a logger. Lines which are no json, e.g.
+// of Plain, are stored as info entries.
+func (r *Recorder) Write(p []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	for scanner.Scan() {
+		r.Log(r.parse(scanner.Text()))
+	}
+	return len(p), nil
+}
+
+// Entries returns a copy of all recorded entries.
+func (r *Recorder) Entries() []log.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]log.Entry{}, r.entries...)
+}
+
+// Reset drops all recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// Find returns all entries of level containing substr in message or fields.
+func (r *Recorder) Find(level log.LogLevel, substr string) []log.Entry {
+	found := []log.Entry{}
+	for _, e := range r.Entries() {
+		if e.Level == level && matches(e, substr) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// Logged reports if an entry of level contains substr.
+func (r *Recorder) Logged(level log.LogLevel, substr string) bool {
+	return len(r.Find(level, substr)) > 0
+}
+
+// AssertLogged fails the test if no entry of level contains substr.
+func (r *Recorder) AssertLogged(t testing.TB, level log.LogLevel, substr string) {
+	t.Helper()
+	if !r.Logged(level, substr) {
+		t.Errorf("expected %s entry containing %q, got:\n%s", level, substr, r.dump())
+	}
+}
+
+// AssertNotLogged fails the test if an entry of level contains substr.
+func (r *Recorder) AssertNotLogged(t testing.TB, level log.LogLevel, substr string) {
+	t.Helper()
+	if r.Logged(level, substr) {
+		t.Errorf("unexpected %s entry containing %q, got:\n%s", level, substr, r.dump())
+	}
+}
+
+// intern
+
+func (r *Recorder) parse(line string) log.Entry {
+	data := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(line), &data); err != nil {
+		return log.Entry{Time: time.Now(), Level: log.LLInfo, Message: line}
+	}
+
+	e := log.Entry{Level: log.LLInfo}
+	if msg, ok := data[r.keys.Message].(string); ok {
+		e.Message = msg
+	}
+	if severity, ok := data[r.keys.Severity].(string); ok {
+		e.Level = log.GetLogLevel(severity)
+	}
+	if ts, ok := data[r.keys.Timestamp].(string); ok {
+		e.Time, _ = time.Parse(time.RFC3339Nano, ts)
+	}
+	delete(data, r.keys.Message)
+	delete(data, r.keys.Severity)
+	delete(data, r.keys.Timestamp)
+	if len(data) > 0 {
+		e.Data = data
+	}
+	return e
+}
+
+func (r *Recorder) dump() string {
+	lines := []string{}
+	for _, e := range r.Entries() {
+		lines = append(lines, "\t"+format(e))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func matches(e log.Entry, substr string) bool {
+	return strings.Contains(format(e), substr)
+}
+
+// format renders e as `level: msg key=value`.
+func format(e log.Entry) string {
+	line := e.Level.String() + ": " + e.Message
+	for _, k := range slices.Sorted(maps.Keys(e.Data)) {
+		line += fmt.Sprintf(" %s=%v", k, e.Data[k])
+	}
+	if e.Err != nil {
+		line += " error=" + e.Err.Error()
+	}
+	return line
+}
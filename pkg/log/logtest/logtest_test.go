@@ -0,0 +1,72 @@
+package logtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+)
+
+func TestCapture(t *testing.T) {
+	r := Capture(t)
+
+	log.Info("user created", "jane")
+	log.Default().With(map[string]any{"attempt": 2}).Warn("retrying")
+	log.Err(errors.New("connection refused"), "cannot connect")
+	log.Plain("plain line")
+
+	r.AssertLogged(t, log.LLInfo, "user created jane")
+	r.AssertLogged(t, log.LLWarn, "attempt=2")
+	r.AssertLogged(t, log.LLError, "error=connection refused")
+	r.AssertLogged(t, log.LLInfo, "plain line")
+	r.AssertNotLogged(t, log.LLError, "user created")
+
+	entries := r.Entries()
+	if len(entries) != 4 || entries[0].Time.IsZero() {
+		t.Error("TestCapture:: unexpected entries", entries)
+	}
+
+	r.Reset()
+	if len(r.Entries()) != 0 {
+		t.Error("TestCapture:: reset should drop entries")
+	}
+}
+
+func TestRecorderSink(t *testing.T) {
+	Capture(t)
+
+	r := NewRecorder()
+	rlog := log.NewRLog("", 0, "info", "", true)
+	rlog.AddDestination("recorder", log.LLDebug, r)
+
+	rlog.Debug("debug only in destination")
+	rlog.ErrD(errors.New("timeout"), map[string]interface{}{"host": "db"}, "query failed")
+
+	r.AssertLogged(t, log.LLDebug, "debug only in destination")
+	if found := r.Find(log.LLError, "host=db"); len(found) != 1 || found[0].Data["error"] != "timeout" {
+		t.Error("TestRecorderSink:: unexpected error entries", found)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	Capture(t)
+
+	for _, protocol := range []log.ConnProtocol{log.Tcp, log.Udp} {
+		c := NewCollector(t, protocol)
+		rlog := c.RLog("info")
+		rlog.InfoD(map[string]interface{}{"n": 1}, "first")
+		rlog.Warn("second")
+		rlog.Flush()
+
+		lines := c.Wait(t, 2, 2*time.Second)
+		entries := c.Entries()
+		if len(lines) != 2 || len(entries) != 2 {
+			t.Fatal("TestCollector:: expected 2 entries", protocol, lines)
+		}
+		if entries[0]["message"] != "first" || entries[0]["n"] != 1.0 || entries[1]["severity"] != "WARN" {
+			t.Error("TestCollector:: unexpected entries", protocol, entries)
+		}
+		rlog.Close()
+	}
+}
@@ -0,0 +1,144 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// levelCounter counts entries per level.
+type levelCounter [LLCritical + 1]atomic.Uint64
+
+func (c *levelCounter) add(level LogLevel) {
+	if level >= LLVerbose && level <= LLCritical {
+		c[level].Add(1)
+	}
+}
+
+var (
+	consoleEntries levelCounter // lines printed by loggers
+	remoteEntries  levelCounter // entries dispatched by rlogs
+)
+
+// EntryCounts returns the number of entries per level of source "console"
+// (printed by loggers) or "remote" (dispatched by rlogs).
+func EntryCounts(source string) map[LogLevel]uint64 {
+	c := &consoleEntries
+	if source == "remote" {
+		c = &remoteEntries
+	}
+	counts := map[LogLevel]uint64{}
+	for level := LLVerbose; level <= LLCritical; level++ {
+		counts[level] = c[level].Load()
+	}
+	return counts
+}
+
+// DestinationStats returns the number of entries handed to each destination
+// by name.
+func (l *RLog) DestinationStats() map[string]uint64 {
+	stats := map[string]uint64{}
+	l.sinkSent.Range(func(name, sent any) bool {
+		stats[name.(string)] = sent.(*atomic.Uint64).Load()
+		return true
+	})
+	return stats
+}
+
+func (l *RLog) sinkCounter(name string) *atomic.Uint64 {
+	if c, ok := l.sinkSent.Load(name); ok {
+		return c.(*atomic.Uint64)
+	}
+	c, _ := l.sinkSent.LoadOrStore(name, &atomic.Uint64{})
+	return c.(*atomic.Uint64)
+}
+
+// http
+
+// MetricsHandler serves the counters in the prometheus text format. Queue
+// stats of the remote endpoint and destination counters are exported for
+// rlogs registered with Register, labeled by the registry name. The remote
+// endpoint is labeled destination="remote".
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes the counters in the prometheus text format.
+func WriteMetrics(w io.Writer) {
+	writeFamily(w, "log_entries_total", "Log entries by source and level.")
+	for _, source := range []string{"console", "remote"} {
+		counts := EntryCounts(source)
+		for level := LLVerbose; level <= LLCritical; level++ {
+			writeSample(w, "log_entries_total", counts[level], "source", source, "level", level.String())
+		}
+	}
+
+	rlogs := registeredRLogs()
+	names := slices.Sorted(maps.Keys(rlogs))
+	counters := []struct {
+		name, help string
+		value      func(s QueueStats) uint64
+	}{
+		{"log_destination_sent_total", "Entries delivered to a destination.", func(s QueueStats) uint64 { return s.Sent }},
+		{"log_destination_dropped_total", "Entries dropped by the queue of a destination.", func(s QueueStats) uint64 { return s.Dropped }},
+		{"log_destination_failed_total", "Entries which could not be delivered.", func(s QueueStats) uint64 { return s.Failed }},
+		{"log_destination_spilled_total", "Entries spilled to disk.", func(s QueueStats) uint64 { return s.Spilled }},
+		{"log_destination_retries_total", "Failed connection or send attempts scheduled for retry.", func(s QueueStats) uint64 { return s.Retries }},
+	}
+	for i, c := range counters {
+		writeFamily(w, c.name, c.help)
+		for _, name := range names {
+			rlog := rlogs[name]
+			if rlog.hasDestination() {
+				writeSample(w, c.name, c.value(rlog.Stats()), "rlog", name, "destination", "remote")
+			}
+			// destinations are synchronous, only sent is counted
+			if i > 0 {
+				continue
+			}
+			stats := rlog.DestinationStats()
+			for _, dest := range slices.Sorted(maps.Keys(stats)) {
+				writeSample(w, c.name, stats[dest], "rlog", name, "destination", dest)
+			}
+		}
+	}
+}
+
+// intern
+
+func registeredRLogs() map[string]*RLog {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	rlogs := map[string]*RLog{}
+	for name, l := range registry.loggers {
+		if rlog, ok := l.(*RLog); ok {
+			rlogs[name] = rlog
+		}
+	}
+	return rlogs
+}
+
+func writeFamily(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
+
+// writeSample writes one line, labels are name value pairs.
+func writeSample(w io.Writer, name string, value uint64, labels ...string) {
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelValue(labels[i+1])+`"`)
+	}
+	fmt.Fprintf(w, "%s{%s} %d\n", name, strings.Join(pairs, ","), value)
+}
+
+func labelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryCounts(t *testing.T) {
+	l := NewLogger(io.Discard, LLInfo)
+	before := EntryCounts("console")
+
+	l.Debug("dropped")
+	l.Error("error")
+	l.Err(io.EOF, "error")
+
+	after := EntryCounts("console")
+	if after[LLError]-before[LLError] != 2 || after[LLDebug] != before[LLDebug] {
+		t.Error("TestEntryCounts:: unexpected console counts", before, after)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+	SetDefault(NewLogger(io.Discard, LLVerbose))
+
+	// nothing listens on the port, entries fail and are retried
+	rlog := NewRLogExt("127.0.0.1", 1, "info", "", true, Tcp, 1)
+	rlog.Backoff = BackoffConfig{Initial: time.Millisecond, Max: time.Millisecond}
+	rlog.Queue.FlushInterval = 10 * time.Millisecond
+	var buf bytes.Buffer
+	rlog.AddDestination("file", LLWarn, NewWriterSink(&buf, TextFormatter))
+	Register("metrics-rlog", rlog)
+	defer rlog.Close()
+
+	rlog.Info("info")
+	rlog.Warn("warn")
+	rlog.Flush()
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE log_entries_total counter",
+		`log_destination_sent_total{rlog="metrics-rlog",destination="remote"} 0`,
+		`log_destination_sent_total{rlog="metrics-rlog",destination="file"} 1`,
+		`log_destination_failed_total{rlog="metrics-rlog",destination="remote"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Error("TestMetricsHandler:: missing line", line, body)
+		}
+	}
+	if !strings.Contains(body, `log_entries_total{source="remote",level="warn"} `) {
+		t.Error("TestMetricsHandler:: missing level counter", body)
+	}
+	if rlog.Stats().Retries == 0 || strings.Contains(body, `log_destination_retries_total{rlog="metrics-rlog",destination="remote"} 0`) {
+		t.Error("TestMetricsHandler:: expected retries", rlog.Stats(), body)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Error("TestMetricsHandler:: unexpected content type", rec.Header())
+	}
+}
+
+func TestMetricsIdleRLog(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+	var console bytes.Buffer
+	SetDefault(NewLogger(&console, LLVerbose))
+
+	// scraping doesn't start delivery or load the tls config
+	rlog := NewRLogExt("127.0.0.1", 6514, "info", "", true, Tls, 1)
+	rlog.Tls = TlsConfig{CAFile: "does-not-exist.pem"}
+	Register("metrics-idle", rlog)
+
+	var buf bytes.Buffer
+	WriteMetrics(&buf)
+	if rlog.queue.Load() != nil || console.Len() > 0 {
+		t.Error("TestMetricsIdleRLog:: delivery started", console.String())
+	}
+	if !strings.Contains(buf.String(), `log_destination_sent_total{rlog="metrics-idle",destination="remote"} 0`+"\n") {
+		t.Error("TestMetricsIdleRLog:: missing zero stats", buf.String())
+	}
+}
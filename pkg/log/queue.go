@@ -0,0 +1,222 @@
+package log
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// drop policy
+
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest queued entry to make room.
+	DropOldest DropPolicy = iota
+	// DropNewest discards the entry which doesn't fit into the queue.
+	DropNewest
+	// Block waits until the entry fits into the queue.
+	Block
+)
+
+// QueueConfig configures the in-memory delivery queue of an RLog.
+type QueueConfig struct {
+	Size          int           // max number of queued entries
+	BatchSize     int           // max number of entries written at once
+	FlushInterval time.Duration // max time an entry waits for a full batch
+	DropPolicy    DropPolicy    // what to do if the queue is full
+}
+
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{Size: 1024, BatchSize: 64, FlushInterval: time.Second, DropPolicy: DropOldest}
+}
+
+// QueueStats counts queued entries by outcome.
+type QueueStats struct {
+	Sent    uint64
+	Dropped uint64
+	Failed  uint64
+	Spilled uint64
+	Retries uint64 // failed connection or send attempts scheduled for retry
+}
+
+// queue buffers entries and hands them in batches to send. A single worker
+// goroutine owns send, so entries are delivered in order. On idle ticks send
+// is called with an empty batch to allow retrying pending work.
+type queue struct {
+	config  QueueConfig
+	send    func(batch [][]byte) error
+	stop    func()
+	entries chan []byte
+	flush   chan chan struct{}
+	close   chan chan struct{}
+	done    chan struct{}
+	closed  atomic.Bool
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+	spilled atomic.Uint64
+	retries atomic.Uint64
+}
+
+// newQueue starts the worker. stop is called by the worker after the last
+// batch was sent on close.
+func newQueue(config QueueConfig, send func(batch [][]byte) error, stop func()) *queue {
+	def := DefaultQueueConfig()
+	if config.Size <= 0 {
+		config.Size = def.Size
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = def.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = def.FlushInterval
+	}
+
+	q := &queue{
+		config:  config,
+		send:    send,
+		stop:    stop,
+		entries: make(chan []byte, config.Size),
+		flush:   make(chan chan struct{}),
+		close:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// push queues entry according to the drop policy.
+func (q *queue) push(entry []byte) {
+	if q.closed.Load() {
+		q.dropped.Add(1)
+		return
+	}
+
+	switch q.config.DropPolicy {
+	case Block:
+		select {
+		case q.entries <- entry:
+		case <-q.done:
+			q.dropped.Add(1)
+		}
+	case DropNewest:
+		select {
+		case q.entries <- entry:
+		default:
+			q.dropped.Add(1)
+		}
+	default:
+		for {
+			select {
+			case q.entries <- entry:
+				return
+			default:
+			}
+			// make room
+			select {
+			case <-q.entries:
+				q.dropped.Add(1)
+			default:
+			}
+		}
+	}
+}
+
+// Flush blocks until all entries queued before the call were handled.
+func (q *queue) Flush() {
+	done := make(chan struct{})
+	select {
+	case q.flush <- done:
+		<-done
+	case <-q.done:
+	}
+}
+
+// Close flushes the queue and stops the worker. Entries pushed afterwards are
+// dropped.
+func (q *queue) Close() {
+	if q.closed.Swap(true) {
+		<-q.done
+		return
+	}
+	done := make(chan struct{})
+	q.close <- done
+	<-done
+}
+
+func (q *queue) Stats() QueueStats {
+	return QueueStats{
+		Sent:    q.sent.Load(),
+		Dropped: q.dropped.Load(),
+		Failed:  q.failed.Load(),
+		Spilled: q.spilled.Load(),
+		Retries: q.retries.Load(),
+	}
+}
+
+// worker
+
+func (q *queue) run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(q.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, q.config.BatchSize)
+	for {
+		select {
+		case entry := <-q.entries:
+			batch = append(batch, entry)
+			if len(batch) >= q.config.BatchSize {
+				batch = q.write(batch)
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				q.send(nil)
+			}
+			batch = q.write(batch)
+		case done := <-q.flush:
+			batch = q.drain(batch)
+			close(done)
+		case done := <-q.close:
+			q.drain(batch)
+			if q.stop != nil {
+				q.stop()
+			}
+			close(done)
+			return
+		}
+	}
+}
+
+// drain writes batch and all currently queued entries.
+func (q *queue) drain(batch [][]byte) [][]byte {
+	for {
+		select {
+		case entry := <-q.entries:
+			batch = append(batch, entry)
+			if len(batch) >= q.config.BatchSize {
+				batch = q.write(batch)
+			}
+		default:
+			return q.write(batch)
+		}
+	}
+}
+
+func (q *queue) write(batch [][]byte) [][]byte {
+	if len(batch) == 0 {
+		return batch
+	}
+	err := q.send(batch)
+	if errors.Is(err, errSpilled) {
+		q.spilled.Add(uint64(len(batch)))
+	} else if err != nil {
+		q.failed.Add(uint64(len(batch)))
+	} else {
+		q.sent.Add(uint64(len(batch)))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,83 @@
+package log
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueueOrderAndBatching(t *testing.T) {
+	var got []string
+	var batches int
+	q := newQueue(QueueConfig{Size: 100, BatchSize: 3, FlushInterval: time.Hour}, func(batch [][]byte) error {
+		batches++
+		for _, e := range batch {
+			got = append(got, string(e))
+		}
+		return nil
+	}, nil)
+
+	for _, e := range []string{"a", "b", "c", "d", "e"} {
+		q.push([]byte(e))
+	}
+	q.Close()
+
+	if len(got) != 5 || got[0] != "a" || got[4] != "e" {
+		t.Error("TestQueueOrderAndBatching:: unexpected entries", got)
+	}
+	if batches != 2 {
+		t.Error("TestQueueOrderAndBatching:: expected 2 batches, got", batches)
+	}
+	if s := q.Stats(); s.Sent != 5 || s.Dropped != 0 || s.Failed != 0 {
+		t.Error("TestQueueOrderAndBatching:: unexpected stats", s)
+	}
+
+	q.push([]byte("f"))
+	if s := q.Stats(); s.Dropped != 1 {
+		t.Error("TestQueueOrderAndBatching:: push after close should drop", s)
+	}
+}
+
+func TestQueueDropPolicy(t *testing.T) {
+	for _, policy := range []DropPolicy{DropOldest, DropNewest} {
+		release := make(chan struct{})
+		var got []string
+		q := newQueue(QueueConfig{Size: 2, BatchSize: 1, FlushInterval: time.Hour, DropPolicy: policy}, func(batch [][]byte) error {
+			<-release
+			got = append(got, string(batch[0]))
+			return nil
+		}, nil)
+
+		// worker blocks on first entry, queue holds two more
+		q.push([]byte("a"))
+		time.Sleep(50 * time.Millisecond)
+		q.push([]byte("b"))
+		q.push([]byte("c"))
+		q.push([]byte("d"))
+		close(release)
+		q.Close()
+
+		want := "c"
+		if policy == DropNewest {
+			want = "b"
+		}
+		if len(got) != 3 || got[1] != want {
+			t.Error("TestQueueDropPolicy:: unexpected entries", policy, got)
+		}
+		if s := q.Stats(); s.Dropped != 1 || s.Sent != 3 {
+			t.Error("TestQueueDropPolicy:: unexpected stats", policy, s)
+		}
+	}
+}
+
+func TestQueueFailed(t *testing.T) {
+	q := newQueue(QueueConfig{}, func(batch [][]byte) error {
+		return errors.New("failed")
+	}, nil)
+	q.push([]byte("a"))
+	q.Flush()
+	if s := q.Stats(); s.Failed != 1 {
+		t.Error("TestQueueFailed:: unexpected stats", s)
+	}
+	q.Close()
+}
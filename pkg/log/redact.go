@@ -0,0 +1,161 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Mask replaces redacted values.
+const Mask = "***"
+
+// DefaultRedactKeys are masked in data maps and key=value pairs of messages.
+// Keys match if they contain one of the values, case insensitive.
+var DefaultRedactKeys = []string{"authorization", "password", "passwd", "token", "secret", "apikey", "api_key", "api-key"}
+
+var (
+	JwtPattern        = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)
+	AwsKeyPattern     = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	EmailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	AuthSchemePattern = regexp.MustCompile(`(?i)\b(?:Bearer|Basic)\s+[A-Za-z0-9._~+/=-]{8,}`)
+)
+
+// Redactor masks sensitive values in messages and data maps.
+type Redactor struct {
+	keys     []string
+	keyValue *regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+// redactor is unset by default, see SetRedactor.
+var redactor atomic.Pointer[Redactor]
+
+// NewRedactor masks values of keys and all matches of patterns.
+func NewRedactor(keys []string, patterns ...*regexp.Regexp) *Redactor {
+	r := &Redactor{patterns: patterns}
+	quoted := []string{}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		r.keys = append(r.keys, strings.ToLower(k))
+		quoted = append(quoted, regexp.QuoteMeta(k))
+	}
+	if len(quoted) > 0 {
+		// key=value, key: value, "key":"value"
+		r.keyValue = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*"?\s*[=:]\s*)("[^"]*"|[^\s,;&]+)`)
+	}
+	return r
+}
+
+// DefaultRedactor masks DefaultRedactKeys, jwts, aws access keys, emails and
+// bearer or basic credentials.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactKeys, AuthSchemePattern, JwtPattern, AwsKeyPattern, EmailPattern)
+}
+
+// SetRedactor sets the redactor applied by all loggers before output, e.g.
+// SetRedactor(DefaultRedactor()). Redaction is disabled by default and by
+// nil.
+func SetRedactor(r *Redactor) {
+	redactor.Store(r)
+}
+
+// GetRedactor returns the redactor applied by all loggers.
+func GetRedactor() *Redactor {
+	return redactor.Load()
+}
+
+// redactEntry masks message and data of e with the redactor set.
+func redactEntry(e Entry) Entry {
+	if r := GetRedactor(); r != nil {
+		e.Message = r.String(e.Message)
+		e.Data = r.Data(e.Data)
+	}
+	return e
+}
+
+// String masks patterns and values of keys in s.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.ReplaceAllString(s, Mask)
+	}
+	if r.keyValue != nil {
+		s = r.keyValue.ReplaceAllStringFunc(s, func(match string) string {
+			groups := r.keyValue.FindStringSubmatch(match)
+			if strings.HasPrefix(groups[2], `"`) {
+				return groups[1] + `"` + Mask + `"`
+			}
+			return groups[1] + Mask
+		})
+	}
+	return s
+}
+
+// Data returns a copy of data with values of keys masked and patterns
+// masked in all strings. Nested maps and slices are redacted as well.
+func (r *Redactor) Data(data map[string]interface{}) map[string]interface{} {
+	if r == nil || data == nil {
+		return data
+	}
+	redacted := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if r.isKey(k) {
+			redacted[k] = Mask
+			continue
+		}
+		redacted[k] = r.value(v)
+	}
+	return redacted
+}
+
+// fields returns a copy of fields redacted like Data.
+func (r *Redactor) fields(fields []field) []field {
+	redacted := make([]field, len(fields))
+	for i, f := range fields {
+		if r.isKey(f.key) {
+			redacted[i] = field{key: f.key, value: Mask}
+			continue
+		}
+		redacted[i] = field{key: f.key, value: r.value(f.value)}
+	}
+	return redacted
+}
+
+func (r *Redactor) isKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		return r.Data(v)
+	case []any:
+		values := make([]any, len(v))
+		for i, item := range v {
+			values[i] = r.value(item)
+		}
+		return values
+	case []string:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = r.String(item)
+		}
+		return values
+	case error, fmt.Stringer:
+		return r.String(fmt.Sprint(v))
+	}
+	return v
+}
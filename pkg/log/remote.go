@@ -1,13 +1,17 @@
 package log
 
 import (
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"net"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,6 +28,33 @@ const (
 	LLCritical
 )
 
+func (l LogLevel) String() string {
+	switch l {
+	case LLVerbose:
+		return "verbose"
+	case LLDebug:
+		return "debug"
+	case LLInfo:
+		return "info"
+	case LLWarn:
+		return "warn"
+	case LLError:
+		return "error"
+	case LLCritical:
+		return "critical"
+	}
+	return "LogLevel(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLogLevel is like GetLogLevel but fails on unknown levels.
+func ParseLogLevel(level string) (LogLevel, error) {
+	l := GetLogLevel(level)
+	if l == LLVerbose && strings.ToLower(level) != "verbose" {
+		return l, errors.New("unknown log level: " + level)
+	}
+	return l, nil
+}
+
 func GetLogLevel(level string) LogLevel {
 	if strings.ToLower(level) == "critical" {
 		return LLCritical
@@ -43,6 +74,23 @@ func GetLogLevel(level string) LogLevel {
 	return LLVerbose
 }
 
+// severity returns the name of the level used in remote payloads.
+func severity(level LogLevel) string {
+	switch level {
+	case LLVerbose:
+		return "VERBOSE"
+	case LLDebug:
+		return "DEBUG"
+	case LLInfo:
+		return "INFO"
+	case LLWarn:
+		return "WARN"
+	case LLError:
+		return "ERROR"
+	}
+	return "CRITICAL"
+}
+
 // connection protocol
 
 type ConnProtocol int
@@ -50,15 +98,35 @@ type ConnProtocol int
 const (
 	Tcp ConnProtocol = iota
 	Udp
+	Unix     // unix stream socket, address is the socket path
+	Unixgram // unix datagram socket, e.g. /dev/log
+	Tls      // tcp secured by tls, see RLog.Tls
 )
 
 func getNetwork(protocol ConnProtocol) string {
-	if protocol == Udp {
+	switch protocol {
+	case Udp:
 		return "udp"
+	case Unix:
+		return "unix"
+	case Unixgram:
+		return "unixgram"
 	}
 	return "tcp"
 }
 
+func isDatagram(protocol ConnProtocol) bool {
+	return protocol == Udp || protocol == Unixgram
+}
+
+// Transport delivers batches of encoded entries instead of the connection
+// to Address, e.g. via http. Send and Close are called from the queue
+// worker only.
+type Transport interface {
+	Send(batch [][]byte) error
+	Close() error
+}
+
 // common keys
 
 type Keys struct {
@@ -76,6 +144,34 @@ type RLog struct {
 	LogLevel   LogLevel
 	TimeFormat string
 	IsUtc      bool
+	Format     Format
+	Syslog     SyslogConfig
+	Framing    Framing // stream protocols only, datagrams are not framed
+	Tls        TlsConfig
+	Transport  Transport
+	Queue      QueueConfig
+	Backoff    BackoffConfig
+	Spill      SpillConfig
+	Sampler    *Sampler // drops repeated entries if set
+	AddCaller  bool     // adds file:line of the calling code as caller
+	AddStack   bool     // adds a stack trace to critical entries
+
+	// additional sinks, configure before logging
+	Destinations []Destination
+
+	levelMu sync.RWMutex
+
+	// entries handed to destinations by name
+	sinkSent sync.Map
+
+	// delivery
+	queueOnce sync.Once
+	queue     atomic.Pointer[queue] // nil until delivery starts
+	conn      net.Conn
+	retry     backoff
+	spill     *spillFile
+	tlsConfig *tls.Config
+	configErr error // entries are not queued if set
 }
 
 func NewRLog(host string, port int, logLevel, timeFormat string, isUtc bool) *RLog {
@@ -88,11 +184,7 @@ func NewRLogExt(host string, port int, logLevel, timeFormat string, isUtc bool,
 		address = host + ":" + strconv.Itoa(port)
 	}
 
-	keys := Keys{
-		Message:   "message",
-		Timestamp: "timestamp",
-		Severity:  "severity",
-	}
+	keys := DefaultKeys()
 
 	tf := timeFormat
 	if tf == "" {
@@ -111,6 +203,9 @@ func NewRLogExt(host string, port int, logLevel, timeFormat string, isUtc bool,
 		LogLevel:   GetLogLevel(logLevel),
 		TimeFormat: tf,
 		IsUtc:      isUtc,
+		Syslog:     DefaultSyslogConfig(),
+		Queue:      DefaultQueueConfig(),
+		Backoff:    DefaultBackoffConfig(),
 	}
 	return rlog
 }
@@ -119,6 +214,25 @@ func (l *RLog) UpdateCommonData(data map[string]interface{}) {
 	l.CommonData = data
 }
 
+// SetLevel changes LogLevel, safe to call while logging.
+func (l *RLog) SetLevel(level LogLevel) {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+	l.LogLevel = level
+}
+
+func (l *RLog) Level() LogLevel {
+	l.levelMu.RLock()
+	defer l.levelMu.RUnlock()
+	return l.LogLevel
+}
+
+// AddDestination adds a sink receiving all entries reaching level. Add
+// destinations before logging.
+func (l *RLog) AddDestination(name string, level LogLevel, sink Sink) {
+	l.Destinations = append(l.Destinations, Destination{Name: name, LogLevel: level, Sink: sink})
+}
+
 func (l *RLog) UpdateKeys(message, severity, timestamp string) {
 	if message != "" {
 		l.Keys.Message = message
@@ -132,189 +246,472 @@ func (l *RLog) UpdateKeys(message, severity, timestamp string) {
 }
 
 func (l *RLog) Verbose(logs ...any) {
-	if l.LogLevel > LLVerbose {
-		return
-	}
-	msg := getMsg(logs...)
-	Verbose(msg)
-	l.sendJsonWithSeverity(msg, nil, "VERBOSE")
+	l.log(LLVerbose, nil, logs)
 }
 
 func (l *RLog) VerboseD(data map[string]interface{}, logs ...any) {
-	if l.LogLevel > LLVerbose {
-		return
-	}
-	msg := getMsg(logs...)
-	Verbose(msg)
-	l.sendJsonWithSeverity(msg, data, "VERBOSE")
+	l.log(LLVerbose, data, logs)
 }
 
 func (l *RLog) Debug(logs ...any) {
-	if l.LogLevel > LLDebug {
-		return
-	}
-
-	msg := getMsg(logs...)
-	Debug(msg)
-	l.sendJsonWithSeverity(msg, nil, "DEBUG")
+	l.log(LLDebug, nil, logs)
 }
 
 func (l *RLog) DebugD(data map[string]interface{}, logs ...any) {
-	if l.LogLevel > LLDebug {
-		return
-	}
-	msg := getMsg(logs...)
-	Debug(msg)
-	l.sendJsonWithSeverity(msg, data, "DEBUG")
+	l.log(LLDebug, data, logs)
 }
 
 func (l *RLog) Info(logs ...any) {
-	if l.LogLevel > LLInfo {
-		return
-	}
-	msg := getMsg(logs...)
-	Info(msg)
-	l.sendJsonWithSeverity(msg, nil, "INFO")
+	l.log(LLInfo, nil, logs)
 }
 
 func (l *RLog) InfoD(data map[string]interface{}, logs ...any) {
-	if l.LogLevel > LLInfo {
-		return
-	}
-	msg := getMsg(logs...)
-	Info(msg)
-	l.sendJsonWithSeverity(msg, data, "INFO")
+	l.log(LLInfo, data, logs)
 }
 
 func (l *RLog) Warn(logs ...any) {
-	if l.LogLevel > LLWarn {
-		return
-	}
-	msg := getMsg(logs...)
-	Warn(msg)
-	l.sendJsonWithSeverity(msg, nil, "WARN")
+	l.log(LLWarn, nil, logs)
 }
 
 func (l *RLog) WarnD(data map[string]interface{}, logs ...any) {
-	if l.LogLevel > LLWarn {
-		return
-	}
-	msg := getMsg(logs...)
-	Warn(msg)
-	l.sendJsonWithSeverity(msg, data, "WARN")
+	l.log(LLWarn, data, logs)
 }
 
 func (l *RLog) Error(logs ...any) {
-	if l.LogLevel > LLError {
-		return
-	}
-	msg := getMsg(logs...)
-	Error(msg)
-	l.sendJsonWithSeverity(msg, nil, "ERROR")
+	l.log(LLError, nil, logs)
 }
 
 func (l *RLog) ErrorD(data map[string]interface{}, logs ...any) {
-	if l.LogLevel > LLError {
-		return
-	}
-	msg := getMsg(logs...)
-	Error(msg)
-	l.sendJsonWithSeverity(msg, data, "ERROR")
+	l.log(LLError, data, logs)
+}
+
+// Err logs err as error field and its wrapped errors as cause.N fields.
+func (l *RLog) Err(err error, logs ...any) {
+	l.logErr(LLError, err, nil, logs)
+}
+
+func (l *RLog) ErrD(err error, data map[string]interface{}, logs ...any) {
+	l.logErr(LLError, err, data, logs)
 }
 
 func (l *RLog) Critical(logs ...any) {
-	if l.LogLevel > LLCritical {
-		return
-	}
-	msg := getMsg(logs...)
-	Critical(msg)
-	l.sendJsonWithSeverity(msg, nil, "CRITICAL")
+	l.log(LLCritical, nil, logs)
 }
 
 func (l *RLog) CriticalD(data map[string]interface{}, logs ...any) {
-	if l.LogLevel > LLCritical {
-		return
-	}
-	msg := getMsg(logs...)
-	Critical(msg)
-	l.sendJsonWithSeverity(msg, data, "CRITICAL")
+	l.log(LLCritical, data, logs)
 }
 
 func (l *RLog) LogString(logs ...any) {
-	msg := getMsg(logs...)
+	msg := GetRedactor().String(getMsg(logs...))
 	l.sendString(msg)
 	Plain(msg)
 }
 
+// Flush emits pending sampler summaries and blocks until all queued entries
+// were delivered or failed.
+func (l *RLog) Flush() {
+	if l.Sampler != nil {
+		l.emitSuppressed(l.Sampler.Flush(), true)
+	}
+	if !l.hasDestination() {
+		return
+	}
+	l.getQueue().Flush()
+}
+
+// Close flushes the queue and closes the connection. Entries logged
+// afterwards are dropped.
+func (l *RLog) Close() {
+	if l.Sampler != nil {
+		l.emitSuppressed(l.Sampler.Flush(), true)
+	}
+	if !l.hasDestination() {
+		return
+	}
+	l.getQueue().Close()
+}
+
+// Start validates the configuration and starts delivery, otherwise this
+// happens on the first entry. If the configuration is invalid, e.g. the tls
+// files can't be loaded, the error is returned and entries are counted as
+// failed instead of being queued.
+func (l *RLog) Start() error {
+	if !l.hasDestination() {
+		return nil
+	}
+	l.getQueue()
+	return l.configErr
+}
+
+// Stats returns the delivery counters of the queue. Stats are zero until
+// delivery started, reading them doesn't start it.
+func (l *RLog) Stats() QueueStats {
+	q := l.queue.Load()
+	if q == nil {
+		return QueueStats{}
+	}
+	return q.Stats()
+}
+
 // private
 
+// log prints to console and sends the entry if level reaches LogLevel and
+// hands it to all destinations accepting level.
+func (l *RLog) log(level LogLevel, data map[string]interface{}, logs []any) {
+	l.logErr(level, nil, data, logs)
+}
+
+func (l *RLog) logErr(level LogLevel, err error, data map[string]interface{}, logs []any) {
+	if !l.enabled(level) {
+		return
+	}
+	if l.AddCaller || (l.AddStack && level >= LLCritical) {
+		data = maps.Clone(data)
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		if l.AddCaller {
+			data[CallerKey] = caller()
+		}
+		if l.AddStack && level >= LLCritical {
+			data[StackKey] = stack()
+		}
+	}
+	l.emit(Entry{Time: time.Now(), Level: level, Message: getMsg(logs...), Data: data, Err: err}, true)
+}
+
+// emit redacts e, applies the sampler and dispatches e. Console output is
+// optional.
+func (l *RLog) emit(e Entry, console bool) {
+	if r := GetRedactor(); r != nil {
+		e.Message = r.String(e.Message)
+	}
+	if l.Sampler != nil {
+		ok, summaries := l.Sampler.sample(e.Level, e.Message, func(summaries []Suppressed) {
+			l.emitSuppressed(summaries, console)
+		})
+		l.emitSuppressed(summaries, console)
+		if !ok {
+			return
+		}
+	}
+	if console && e.Level >= l.Level() {
+		if e.Err != nil {
+			Default().Err(e.Err, e.Message)
+		} else {
+			Default().log(e.Level, levelLabel(e.Level), levelColor(e.Level), []any{e.Message})
+		}
+	}
+	e = e.withError()
+	if r := GetRedactor(); r != nil {
+		e.Data = r.Data(e.Data)
+	}
+	l.dispatch(e)
+}
+
+func (l *RLog) emitSuppressed(summaries []Suppressed, console bool) {
+	for _, s := range summaries {
+		e := Entry{Time: time.Now(), Level: s.Level, Message: s.String(), Data: map[string]interface{}{"suppressed": s.Count}}
+		if console && e.Level >= l.Level() {
+			Default().log(e.Level, levelLabel(e.Level), levelColor(e.Level), []any{e.Message})
+		}
+		l.dispatch(e)
+	}
+}
+
+// enabled reports if level reaches LogLevel or the level of any destination.
+func (l *RLog) enabled(level LogLevel) bool {
+	if level >= l.Level() {
+		return true
+	}
+	for _, d := range l.Destinations {
+		if level >= d.LogLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch sends e to the remote endpoint and all destinations.
+func (l *RLog) dispatch(e Entry) {
+	remoteEntries.add(e.Level)
+	if e.Level >= l.Level() {
+		l.sendJsonAt(e.Time, e.Message, e.Data, severity(e.Level))
+	}
+	for _, d := range l.Destinations {
+		if e.Level >= d.LogLevel {
+			d.Sink.Log(e)
+			l.sinkCounter(d.Name).Add(1)
+		}
+	}
+}
+
+func (l *RLog) hasDestination() bool {
+	return l.Address != "" || l.Transport != nil
+}
+
+func (l *RLog) getQueue() *queue {
+	l.queueOnce.Do(func() {
+		l.retry = backoff{config: l.Backoff}
+		if l.Protocol == Tls {
+			config, err := l.Tls.Config()
+			if err != nil {
+				l.configErr = fmt.Errorf("invalid tls config: %w", err)
+				Err(err, "invalid tls config for remote logging")
+			}
+			l.tlsConfig = config
+		}
+		if l.Spill.Path != "" {
+			l.spill = &spillFile{config: l.Spill}
+		}
+		l.queue.Store(newQueue(l.Queue, l.writeBatch, l.stop))
+	})
+	return l.queue.Load()
+}
+
 func (l *RLog) connect() net.Conn {
+	dialer := &net.Dialer{Timeout: l.Timeout * time.Second}
+
+	if l.Protocol == Tls {
+		if l.tlsConfig == nil {
+			return nil
+		}
+		conn, err := tls.DialWithDialer(dialer, "tcp", l.Address, l.tlsConfig)
+		if err != nil {
+			if os.Getenv("GU_REMOTE_LOG_DEBUG") == "true" {
+				fmt.Println("Tls handshake failed:", err)
+			}
+			return nil
+		}
+		return conn
+	}
+
 	network := getNetwork(l.Protocol)
-	conn, err := net.Dial(network, l.Address)
+	conn, err := dialer.Dial(network, l.Address)
 	if err != nil {
 		// Err(err, "dial to network", network, "address", l.Address)
 		return nil
 	}
+	return conn
+}
 
-	// update deadlines
-	deadline := time.Now().Add(l.Timeout * time.Second)
-	conn.SetDeadline(deadline)
-	conn.SetWriteDeadline(deadline)
-	conn.SetReadDeadline(deadline)
+func (l *RLog) disconnect() {
+	if l.conn != nil {
+		l.conn.Close()
+		l.conn = nil
+	}
+}
 
-	return conn
+func (l *RLog) stop() {
+	l.disconnect()
+	if l.Transport != nil {
+		l.Transport.Close()
+	}
+}
+
+// writeBatch is called by the queue worker only. The connection is kept
+// open between batches and recreated with backoff after errors. Entries
+// which cannot be delivered are spilled to disk if configured and replayed
+// before newer entries. An empty batch only retries spilled entries.
+func (l *RLog) writeBatch(batch [][]byte) error {
+	if len(batch) == 0 && (l.spill == nil || l.spill.size() == 0) {
+		return nil
+	}
+
+	if !l.ensureConn() {
+		if os.Getenv("GU_REMOTE_LOG_DEBUG") == "true" {
+			fmt.Println("No connection for remote logging.")
+		}
+		return l.spillBatch(batch, errors.New("no connection to "+l.Address))
+	}
+
+	// replay spilled entries first to keep order
+	if err := l.replay(); err != nil {
+		return l.spillBatch(batch, err)
+	}
+
+	if err := l.writeEntries(batch); err != nil {
+		Err(err, "cannot write payload")
+		return l.spillBatch(batch, err)
+	}
+	return nil
+}
+
+// ensureConn returns true if there is a usable connection or the transport
+// may be used again.
+func (l *RLog) ensureConn() bool {
+	if l.Transport != nil {
+		return l.retry.ready()
+	}
+	if l.conn != nil && l.isAlive() {
+		return true
+	}
+	l.disconnect()
+
+	if !l.retry.ready() {
+		return false
+	}
+	l.conn = l.connect()
+	if l.conn == nil {
+		l.fail()
+		return false
+	}
+	l.retry.reset()
+	return true
+}
+
+// fail delays the next connection or send attempt.
+func (l *RLog) fail() {
+	l.retry.fail()
+	l.queue.Load().retries.Add(1)
+}
+
+// isAlive detects tcp connections closed by the peer, e.g. on collector
+// restarts. Writes to those would succeed once and get lost.
+func (l *RLog) isAlive() bool {
+	if isDatagram(l.Protocol) {
+		return true
+	}
+
+	l.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	defer l.conn.SetReadDeadline(time.Time{})
+
+	var buf [1]byte
+	_, err := l.conn.Read(buf[:])
+	var netErr net.Error
+	return err == nil || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+func (l *RLog) writeEntries(entries [][]byte) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if l.Transport != nil {
+		if err := l.Transport.Send(entries); err != nil {
+			l.fail()
+			return err
+		}
+		l.retry.reset()
+		return nil
+	}
+
+	// update deadline
+	l.conn.SetWriteDeadline(time.Now().Add(l.Timeout * time.Second))
+
+	var err error
+	if isDatagram(l.Protocol) {
+		// one unframed datagram per entry (RFC 5426)
+		for _, entry := range entries {
+			if _, err = l.conn.Write(entry); err != nil {
+				break
+			}
+		}
+	} else {
+		var buf []byte
+		for _, entry := range entries {
+			buf = l.Framing.frame(buf, entry)
+		}
+		_, err = l.conn.Write(buf)
+	}
+	if err != nil {
+		l.disconnect()
+		l.fail()
+	}
+	return err
+}
+
+// replay sends spilled entries. Undelivered entries stay on disk.
+func (l *RLog) replay() error {
+	if l.spill == nil || l.spill.size() == 0 {
+		return nil
+	}
+
+	entries, err := l.spill.read()
+	if err != nil {
+		return err
+	}
+	q := l.queue.Load()
+	batchSize := q.config.BatchSize
+	for i := 0; i < len(entries); i += batchSize {
+		end := min(i+batchSize, len(entries))
+		if err := l.writeEntries(entries[i:end]); err != nil {
+			l.spill.replace(entries[i:])
+			return err
+		}
+		q.sent.Add(uint64(end - i))
+	}
+	return l.spill.replace(nil)
+}
+
+// spillBatch stores batch on disk. Returns errSpilled on success, err if
+// spilling is disabled or fails.
+func (l *RLog) spillBatch(batch [][]byte, err error) error {
+	if l.spill == nil || len(batch) == 0 {
+		return err
+	}
+
+	dropped, spillErr := l.spill.append(batch)
+	l.queue.Load().dropped.Add(uint64(dropped))
+	if spillErr != nil {
+		Err(spillErr, "cannot spill entries to", l.Spill.Path)
+		return err
+	}
+	return errSpilled
 }
 
 func (l *RLog) sendJsonWithSeverity(msg string, add map[string]interface{}, severity string) {
-	if l.Address == "" {
+	l.sendJsonAt(time.Now(), msg, add, severity)
+}
+
+func (l *RLog) sendJsonAt(now time.Time, msg string, add map[string]interface{}, severity string) {
+	if !l.hasDestination() {
 		if os.Getenv("GU_REMOTE_LOG_DEBUG") == "true" {
 			fmt.Println("No address for remote logging.")
 		}
 		return
 	}
 
-	// create map
-	data := map[string]interface{}{}
-	data[l.Keys.Severity] = severity
-	data[l.Keys.Message] = msg
+	if l.Format != FormatJson {
+		l.sendSyslog(now, msg, add, severity)
+		return
+	}
 
 	// timestamp
-	var ts time.Time
+	ts := now
 	if l.IsUtc {
-		ts = time.Now().UTC()
-	} else {
-		ts = time.Now()
+		ts = now.UTC()
 	}
-	data[l.Keys.Timestamp] = ts.Format(l.TimeFormat)
 
-	// copy additional
+	data := entryData(l.Keys, ts.Format(l.TimeFormat), msg, severity, add, l.commonData())
+	l.sendJson(data)
+}
+
+func (l *RLog) sendSyslog(now time.Time, msg string, add map[string]interface{}, severity string) {
+	data := map[string]interface{}{}
 	if add != nil {
 		maps.Copy(data, add)
 	}
-	if l.CommonData != nil {
-		maps.Copy(data, l.CommonData)
+	if common := l.commonData(); common != nil {
+		maps.Copy(data, common)
 	}
 
-	go l.sendJson(data)
+	payload := l.encodeSyslog(now, GetLogLevel(severity), msg, data)
+	if os.Getenv("GU_REMOTE_LOG_DEBUG") == "true" {
+		fmt.Println(string(payload))
+	}
+	l.push(payload)
 }
 
 func (l *RLog) sendJson(data map[string]interface{}) bool {
-	if l.Address == "" {
+	if !l.hasDestination() {
 		if os.Getenv("GU_REMOTE_LOG_DEBUG") == "true" {
 			fmt.Println("No address for remote logging.")
 		}
 		return false
 	}
 
-	conn := l.connect()
-	if conn == nil {
-		if os.Getenv("GU_REMOTE_LOG_DEBUG") == "true" {
-			fmt.Println("No connection for remote logging.")
-		}
-		return false
-	}
-	defer conn.Close()
-
 	payload, err := json.Marshal(data)
 	if err != nil {
 		Err(err, "cannot marshal data", data)
@@ -325,16 +722,12 @@ func (l *RLog) sendJson(data map[string]interface{}) bool {
 		fmt.Println(string(payload))
 	}
 
-	_, err = conn.Write(payload)
-	if err != nil {
-		Err(err, "cannot write payload")
-		return false
-	}
+	l.push(payload)
 	return true
 }
 
 func (l *RLog) sendString(data string) bool {
-	if l.Address == "" {
+	if !l.hasDestination() {
 		return false
 	}
 
@@ -342,18 +735,41 @@ func (l *RLog) sendString(data string) bool {
 		fmt.Println(data)
 	}
 
-	conn := l.connect()
-	if conn == nil {
-		return false
+	l.push([]byte(data))
+	return true
+}
+
+// commonData returns CommonData masked by the redactor.
+func (l *RLog) commonData() map[string]interface{} {
+	return GetRedactor().Data(l.CommonData)
+}
+
+// push queues payload unless the configuration is invalid.
+func (l *RLog) push(payload []byte) {
+	q := l.getQueue()
+	if l.configErr != nil {
+		q.failed.Add(1)
+		return
 	}
-	defer conn.Close()
+	q.push(payload)
+}
 
-	_, err := conn.Write([]byte(data))
-	if err != nil {
-		Err(err, "cannot write string")
-		return false
+// entryData returns the json payload of an entry. Common data overwrite
+// additional data.
+func entryData(keys Keys, ts, msg, severity string, add, common map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{}
+	data[keys.Severity] = severity
+	data[keys.Message] = msg
+	data[keys.Timestamp] = ts
+
+	// copy additional
+	if add != nil {
+		maps.Copy(data, add)
 	}
-	return true
+	if common != nil {
+		maps.Copy(data, common)
+	}
+	return data
 }
 
 func getMsg(logs ...any) string {
@@ -0,0 +1,245 @@
+// Package rotate provides a file writer which rotates by size and/or time,
+// compresses rotated files and removes old backups. It can be used as output
+// of a log.Logger or a log.WriterSink.
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/filesystem"
+	"github.com/nice-pink/goutil/pkg/log"
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+type Config struct {
+	Path       string        // file to write to
+	MaxBytes   int64         // rotate before exceeding size, 0 disables
+	Interval   time.Duration // rotate after interval, 0 disables
+	MaxBackups int           // keep N rotated files, 0 keeps all
+	MaxAge     time.Duration // remove rotated files older than age, rounded up to seconds, 0 keeps all
+	Compress   bool          // gzip rotated files
+}
+
+// Writer is a rotating file writer. It is safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	config Config
+	file   *os.File
+	size   int64
+	opened time.Time
+	last   time.Time // of the newest backup name
+
+	// background compression and cleanup
+	wg sync.WaitGroup
+	bg sync.Mutex
+}
+
+// New opens or creates the file at config.Path for appending.
+func New(config Config) (*Writer, error) {
+	w := &Writer{config: config}
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0755); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.due(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it to a backup and opens a new file.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// Close closes the file and waits for pending compression and cleanup.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// intern
+
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	w.opened = time.Now()
+	return nil
+}
+
+// due reports if the file must be rotated before writing n bytes.
+func (w *Writer) due(n int64) bool {
+	if w.config.MaxBytes > 0 && w.size > 0 && w.size+n > w.config.MaxBytes {
+		return true
+	}
+	return w.config.Interval > 0 && time.Since(w.opened) >= w.config.Interval
+}
+
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+
+	// names must increase, retention relies on their order
+	t := time.Now().Truncate(time.Millisecond)
+	if !t.After(w.last) {
+		t = w.last.Add(time.Millisecond)
+	}
+	backup := w.backupName(t)
+	for filesystem.FileExists(backup) || filesystem.FileExists(backup+".gz") {
+		t = t.Add(time.Millisecond)
+		backup = w.backupName(t)
+	}
+	w.last = t
+	if err := os.Rename(w.config.Path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.bg.Lock()
+		defer w.bg.Unlock()
+
+		if w.config.Compress {
+			if err := compress(backup); err != nil {
+				log.Err(err, "cannot compress", backup)
+			}
+		}
+		w.cleanup()
+	}()
+	return nil
+}
+
+// backupName returns e.g. app-20240301T120000.000.log for app.log.
+func (w *Writer) backupName(t time.Time) string {
+	ext := filepath.Ext(w.config.Path)
+	base := strings.TrimSuffix(w.config.Path, ext)
+	return base + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// backups returns all rotated files, oldest first.
+func (w *Writer) backups(olderThan time.Duration) []string {
+	ext := filepath.Ext(w.config.Path)
+	prefix := strings.TrimSuffix(filepath.Base(w.config.Path), ext) + "-"
+
+	// round up, 0 seconds would list all files
+	seconds := int64(math.Ceil(olderThan.Seconds()))
+	files := filesystem.ListFiles(filepath.Dir(w.config.Path), seconds, true)
+	backups := []string{}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".gz")
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, ts); err != nil {
+			continue
+		}
+		backups = append(backups, file)
+	}
+	// timestamps sort lexically
+	slices.SortFunc(backups, func(a, b string) int {
+		return strings.Compare(strings.TrimSuffix(a, ".gz"), strings.TrimSuffix(b, ".gz"))
+	})
+	return backups
+}
+
+func (w *Writer) cleanup() {
+	remove := []string{}
+	if w.config.MaxAge > 0 {
+		remove = append(remove, w.backups(w.config.MaxAge)...)
+	}
+	if w.config.MaxBackups > 0 {
+		backups := w.backups(0)
+		if len(backups) > w.config.MaxBackups {
+			remove = append(remove, backups[:len(backups)-w.config.MaxBackups]...)
+		}
+	}
+
+	slices.Sort(remove)
+	for _, file := range slices.Compact(remove) {
+		if err := filesystem.DeleteFile(file); err != nil && !os.IsNotExist(err) {
+			log.Err(err, "cannot remove", file)
+		}
+	}
+}
+
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
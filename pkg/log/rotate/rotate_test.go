@@ -0,0 +1,137 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nice-pink/goutil/pkg/log"
+)
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := New(Config{Path: path, MaxBytes: 10, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"line-0001\n", "line-0002\n", "line-0003\n", "line-0004\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := os.ReadFile(path)
+	if string(current) != "line-0004\n" {
+		t.Error("TestRotateBySize:: unexpected current file", string(current))
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if len(backups) != 2 {
+		t.Fatal("TestRotateBySize:: expected 2 compressed backups, got", backups)
+	}
+	if content := readGzip(t, backups[1]); content != "line-0003\n" {
+		t.Error("TestRotateBySize:: newest backup should hold line 3, got", content)
+	}
+	if content := readGzip(t, backups[0]); content != "line-0002\n" {
+		t.Error("TestRotateBySize:: oldest kept backup should hold line 2, got", content)
+	}
+}
+
+func TestRotateByInterval(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(Config{Path: filepath.Join(dir, "app.log"), Interval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	time.Sleep(30 * time.Millisecond)
+	w.Write([]byte("second\n"))
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 1 {
+		t.Error("TestRotateByInterval:: expected 1 backup, got", backups)
+	}
+}
+
+func TestRotateSubSecondMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(Config{Path: filepath.Join(dir, "app.log"), MaxAge: 500 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Write([]byte("first\n"))
+	w.Rotate()
+	w.Close()
+
+	// backup is younger than a second
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 1 {
+		t.Error("TestRotateSubSecondMaxAge:: fresh backup should be kept, got", backups)
+	}
+}
+
+func TestConcurrentLogger(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	dir := t.TempDir()
+	w, err := New(Config{Path: filepath.Join(dir, "app.log"), MaxBytes: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.NewLogger(w, log.LLInfo)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				logger.Info("worker", i)
+			}
+		}()
+	}
+	wg.Wait()
+	w.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "app*.log"))
+	lines := 0
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if !strings.Contains(line, "INFO: worker") {
+				t.Fatal("TestConcurrentLogger:: broken line", line)
+			}
+			lines++
+		}
+	}
+	if lines != 500 {
+		t.Error("TestConcurrentLogger:: expected 500 lines, got", lines)
+	}
+}
+
+func readGzip(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(gz)
+	return string(data)
+}
@@ -0,0 +1,163 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SamplingConfig configures a Sampler. Per interval the first First entries
+// with the same level and message are logged, afterwards every
+// Thereafter-th entry. Thereafter 0 drops all following entries.
+type SamplingConfig struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+func DefaultSamplingConfig() SamplingConfig {
+	return SamplingConfig{Interval: time.Second, First: 10, Thereafter: 100}
+}
+
+// Suppressed summarizes entries dropped by a Sampler in one interval.
+type Suppressed struct {
+	Level   LogLevel
+	Message string
+	Count   int
+}
+
+func (s Suppressed) String() string {
+	return fmt.Sprintf("suppressed %d similar messages: %s", s.Count, s.Message)
+}
+
+type sampleKey struct {
+	level LogLevel
+	msg   string
+}
+
+type sampleCount struct {
+	seen       int
+	suppressed int
+}
+
+// Sampler drops repeated entries keyed by level and message. It is safe for
+// concurrent use. Loggers and RLogs using the sampler also emit summaries
+// when an interval ends without further entries.
+type Sampler struct {
+	mu     sync.Mutex
+	config SamplingConfig
+	start  time.Time
+	counts map[sampleKey]*sampleCount
+	order  []sampleKey
+
+	// emits summaries at the end of an interval
+	timer *time.Timer
+	emit  func([]Suppressed)
+}
+
+func NewSampler(config SamplingConfig) *Sampler {
+	if config.Interval <= 0 {
+		config.Interval = DefaultSamplingConfig().Interval
+	}
+	return &Sampler{config: config, start: time.Now(), counts: map[sampleKey]*sampleCount{}}
+}
+
+// Sample reports if the entry should be logged. If a new interval started,
+// the summaries of the previous interval are returned and should be logged
+// before the entry.
+func (s *Sampler) Sample(level LogLevel, msg string) (bool, []Suppressed) {
+	return s.sample(level, msg, nil)
+}
+
+// Flush returns the summaries of the current interval and starts a new one.
+func (s *Sampler) Flush() []Suppressed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return s.reset()
+}
+
+// intern
+
+// sample is Sample, emit receives the summaries of suppressed entries if no
+// entry follows within the interval.
+func (s *Sampler) sample(level LogLevel, msg string, emit func([]Suppressed)) (bool, []Suppressed) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []Suppressed
+	if time.Since(s.start) >= s.config.Interval {
+		summaries = s.reset()
+	}
+
+	key := sampleKey{level: level, msg: msg}
+	count, ok := s.counts[key]
+	if !ok {
+		count = &sampleCount{}
+		s.counts[key] = count
+		s.order = append(s.order, key)
+	}
+	count.seen++
+
+	if count.seen <= s.config.First {
+		return true, summaries
+	}
+	if s.config.Thereafter > 0 && (count.seen-s.config.First)%s.config.Thereafter == 0 {
+		return true, summaries
+	}
+	count.suppressed++
+	if emit != nil {
+		s.emit = emit
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.config.Interval-time.Since(s.start), s.tick)
+		}
+	}
+	return false, summaries
+}
+
+// tick emits the summaries of an interval that ended without further
+// entries.
+func (s *Sampler) tick() {
+	s.mu.Lock()
+	s.timer = nil
+	var summaries []Suppressed
+	if remaining := s.config.Interval - time.Since(s.start); remaining > 0 {
+		// interval was restarted meanwhile
+		if s.hasSuppressed() {
+			s.timer = time.AfterFunc(remaining, s.tick)
+		}
+	} else {
+		summaries = s.reset()
+	}
+	emit := s.emit
+	s.mu.Unlock()
+
+	if len(summaries) > 0 && emit != nil {
+		emit(summaries)
+	}
+}
+
+func (s *Sampler) hasSuppressed() bool {
+	for _, count := range s.counts {
+		if count.suppressed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Sampler) reset() []Suppressed {
+	var summaries []Suppressed
+	for _, key := range s.order {
+		if count := s.counts[key]; count.suppressed > 0 {
+			summaries = append(summaries, Suppressed{Level: key.level, Message: key.msg, Count: count.suppressed})
+		}
+	}
+	s.start = time.Now()
+	s.counts = map[sampleKey]*sampleCount{}
+	s.order = nil
+	return summaries
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	s := NewSampler(SamplingConfig{Interval: time.Hour, First: 2, Thereafter: 3})
+
+	logged := 0
+	for range 10 {
+		if ok, _ := s.Sample(LLError, "boom"); ok {
+			logged++
+		}
+	}
+	// 1, 2, 5, 8
+	if logged != 4 {
+		t.Error("TestSampler:: expected 4 logged entries, got", logged)
+	}
+	if ok, _ := s.Sample(LLWarn, "boom"); !ok {
+		t.Error("TestSampler:: other level should be sampled separately")
+	}
+
+	summaries := s.Flush()
+	if len(summaries) != 1 || summaries[0].Count != 6 || summaries[0].Level != LLError {
+		t.Error("TestSampler:: unexpected summaries", summaries)
+	}
+	if ok, _ := s.Sample(LLError, "boom"); !ok {
+		t.Error("TestSampler:: flush should start a new interval")
+	}
+}
+
+func TestSamplerInterval(t *testing.T) {
+	s := NewSampler(SamplingConfig{Interval: 20 * time.Millisecond, First: 1})
+	s.Sample(LLError, "boom")
+	s.Sample(LLError, "boom")
+	time.Sleep(30 * time.Millisecond)
+
+	ok, summaries := s.Sample(LLError, "boom")
+	if !ok || len(summaries) != 1 || summaries[0].String() != "suppressed 1 similar messages: boom" {
+		t.Error("TestSamplerInterval:: unexpected result", ok, summaries)
+	}
+}
+
+func TestLoggerSampling(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LLVerbose)
+	l.SetSampler(NewSampler(SamplingConfig{Interval: time.Hour, First: 1}))
+	for range 5 {
+		l.Error("boom")
+	}
+	l.Info("other")
+	l.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "ERROR: boom") || !strings.HasSuffix(lines[2], "ERROR: suppressed 4 similar messages: boom") {
+		t.Error("TestLoggerSampling:: unexpected output", lines)
+	}
+}
+
+// lineWriter passes written lines to a channel.
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestLoggerSamplingBurst(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	lines := make(lineWriter, 10)
+	l := NewLogger(lines, LLVerbose)
+	l.SetSampler(NewSampler(SamplingConfig{Interval: 20 * time.Millisecond, First: 1}))
+	for range 5 {
+		l.Error("boom")
+	}
+	<-lines
+
+	// burst stops, summary follows at the end of the interval
+	select {
+	case line := <-lines:
+		if !strings.HasSuffix(strings.TrimSpace(line), "ERROR: suppressed 4 similar messages: boom") {
+			t.Error("TestLoggerSamplingBurst:: unexpected summary", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TestLoggerSamplingBurst:: missing summary")
+	}
+	select {
+	case line := <-lines:
+		t.Error("TestLoggerSamplingBurst:: unexpected line", line)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// entrySink passes entries to a channel.
+type entrySink chan Entry
+
+func (s entrySink) Log(e Entry) {
+	s <- e
+}
+
+func TestRLogSamplingBurst(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+	SetDefault(NewLogger(make(lineWriter, 10), LLVerbose))
+
+	entries := make(entrySink, 10)
+	rlog := NewRLog("", 0, "info", "", true)
+	rlog.Sampler = NewSampler(SamplingConfig{Interval: 20 * time.Millisecond, First: 1})
+	rlog.AddDestination("sink", LLInfo, entries)
+	for range 3 {
+		rlog.Error("boom")
+	}
+	<-entries
+
+	select {
+	case e := <-entries:
+		if e.Message != "suppressed 2 similar messages: boom" || e.Data["suppressed"] != 2 {
+			t.Error("TestRLogSamplingBurst:: unexpected summary", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TestRLogSamplingBurst:: missing summary")
+	}
+}
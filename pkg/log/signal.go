@@ -0,0 +1,40 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleLevelSignals switches all registered loggers to level on SIGUSR1 and
+// restores the previous levels on SIGUSR2. Call the returned function to stop
+// handling the signals.
+func HandleLevelSignals(level LogLevel) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == syscall.SIGUSR1 {
+					raise(level)
+					Default().Info("Received", sig, "-> log level", level)
+				} else {
+					restore()
+					Default().Info("Received", sig, "-> restored log levels")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package log
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLevelSignals(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+	SetDefault(NewLogger(&bytes.Buffer{}, LLError))
+
+	stop := HandleLevelSignals(LLDebug)
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitFor(t, func() bool { return Default().Level() == LLDebug })
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitFor(t, func() bool { return Default().Level() == LLError })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for range 100 {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("condition not met")
+}
@@ -0,0 +1,6 @@
+package log
+
+// HandleLevelSignals is a no-op, windows has no SIGUSR1/SIGUSR2.
+func HandleLevelSignals(level LogLevel) func() {
+	return func() {}
+}
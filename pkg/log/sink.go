@@ -0,0 +1,129 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is a single log entry handed to sinks.
+type Entry struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	Data    map[string]interface{}
+	Err     error // optional, rendered as error and cause.N fields
+}
+
+// Sink receives entries. *Logger, *RLog and *WriterSink are sinks.
+type Sink interface {
+	Log(e Entry)
+}
+
+// Destination is an additional sink of an RLog with its own minimum level.
+type Destination struct {
+	Name     string
+	LogLevel LogLevel
+	Sink     Sink
+}
+
+// Formatter renders an entry including the trailing newline.
+type Formatter func(e Entry) []byte
+
+// TextFormatter renders `2006-01-02 15:04:05 INFO: msg key=value` without
+// colors.
+func TextFormatter(e Entry) []byte {
+	e = e.withError()
+	line := e.Time.Format(time.DateTime) + " " + levelLabel(e.Level) + ": " + e.Message
+	if len(e.Data) > 0 {
+		line += " " + formatFields(e.Data)
+	}
+	return []byte(line + "\n")
+}
+
+// JsonFormatter renders entries as json lines using keys like an RLog.
+func JsonFormatter(keys Keys, timeFormat string, isUtc bool) Formatter {
+	if timeFormat == "" {
+		timeFormat = time.DateTime
+	}
+	return func(e Entry) []byte {
+		e = e.withError()
+		ts := e.Time
+		if isUtc {
+			ts = ts.UTC()
+		}
+		data := entryData(keys, ts.Format(timeFormat), e.Message, severity(e.Level), e.Data, nil)
+		payload, err := json.Marshal(data)
+		if err != nil {
+			payload, _ = json.Marshal(entryData(keys, ts.Format(timeFormat), e.Message, severity(e.Level), nil, nil))
+		}
+		return append(payload, '\n')
+	}
+}
+
+// writer sink
+
+// WriterSink writes formatted entries to an io.Writer, e.g. a file.
+type WriterSink struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format Formatter
+}
+
+// NewWriterSink returns a sink writing to out. Entries are rendered by format
+// (TextFormatter if nil).
+func NewWriterSink(out io.Writer, format Formatter) *WriterSink {
+	if format == nil {
+		format = TextFormatter
+	}
+	return &WriterSink{out: out, format: format}
+}
+
+// NewFileSink returns a sink appending to the file at path.
+func NewFileSink(path string, format Formatter) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterSink(file, format), nil
+}
+
+func (s *WriterSink) Log(e Entry) {
+	e = e.withError()
+	if r := GetRedactor(); r != nil {
+		e.Message = r.String(e.Message)
+		e.Data = r.Data(e.Data)
+	}
+	line := s.format(e)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(line)
+}
+
+// Close closes the writer if it is an io.Closer.
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closer, ok := s.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// logger and rlog
+
+// Log prints e if its level is enabled. Data are printed as fields.
+func (l *Logger) Log(e Entry) {
+	if !l.Enabled(e.Level) || !l.sample(e.Level, []any{e.Message}) {
+		return
+	}
+	l.print(e.Time, e.Level, levelLabel(e.Level), levelColor(e.Level), []any{e.Message}, append(sortedFields(e.Data), errorFields(e.Err)...))
+}
+
+// Log sends e to the remote endpoint and the destinations of the RLog.
+// Message and data are redacted like entries of the level methods.
+func (l *RLog) Log(e Entry) {
+	l.dispatch(redactEntry(e.withError()))
+}
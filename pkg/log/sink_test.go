@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRLogDestinations(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	prev := Default()
+	defer SetDefault(prev)
+	var console bytes.Buffer
+	SetDefault(NewLogger(&console, LLVerbose))
+
+	path := filepath.Join(t.TempDir(), "debug.log")
+	file, err := NewFileSink(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var collector bytes.Buffer
+	rlog := NewRLog("", 0, "error", "", true)
+	rlog.AddDestination("file", LLDebug, file)
+	rlog.AddDestination("collector", LLWarn, NewWriterSink(&collector, JsonFormatter(rlog.Keys, "", true)))
+
+	rlog.Verbose("verbose")
+	rlog.Debug("debug")
+	rlog.WarnD(map[string]interface{}{"id": 1}, "warn")
+	rlog.Error("error")
+	file.Close()
+
+	// console follows LogLevel of the rlog
+	if strings.Contains(console.String(), "debug") || strings.Contains(console.String(), "warn") || !strings.Contains(console.String(), "ERROR: error") {
+		t.Error("TestRLogDestinations:: unexpected console output", console.String())
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "DEBUG: debug") || !strings.HasSuffix(lines[1], "WARNING: warn id=1") {
+		t.Error("TestRLogDestinations:: unexpected file content", lines)
+	}
+
+	lines = strings.Split(strings.TrimSpace(collector.String()), "\n")
+	var m map[string]any
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[0]), &m) != nil || m["message"] != "warn" || m["severity"] != "WARN" || m["id"] != 1.0 {
+		t.Error("TestRLogDestinations:: unexpected collector content", lines)
+	}
+}
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// LevelFromSlog maps a slog level to the closest log level.
+func LevelFromSlog(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return LLVerbose
+	case level < slog.LevelInfo:
+		return LLDebug
+	case level < slog.LevelWarn:
+		return LLInfo
+	case level < slog.LevelError:
+		return LLWarn
+	case level == slog.LevelError:
+		return LLError
+	}
+	return LLCritical
+}
+
+// SlogLevel maps the log level to a slog level.
+func (l LogLevel) SlogLevel() slog.Level {
+	switch l {
+	case LLVerbose:
+		return slog.LevelDebug - 4
+	case LLDebug:
+		return slog.LevelDebug
+	case LLInfo:
+		return slog.LevelInfo
+	case LLWarn:
+		return slog.LevelWarn
+	case LLError:
+		return slog.LevelError
+	}
+	return slog.LevelError + 4
+}
+
+// console
+
+// ConsoleHandler is a slog.Handler printing records in the console format
+// of the Logger it wraps, e.g. `2006-01-02 15:04:05 INFO: msg key=value`.
+type ConsoleHandler struct {
+	logger *Logger
+	attrs  []field
+	group  string
+}
+
+// NewConsoleHandler returns a handler writing to logger (default logger if nil).
+func NewConsoleHandler(logger *Logger) *ConsoleHandler {
+	if logger == nil {
+		logger = Default()
+	}
+	return &ConsoleHandler{logger: logger}
+}
+
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(LevelFromSlog(level))
+}
+
+func (h *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	fields := append(sortedFields(Fields(ctx)), h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.group, a)
+		return true
+	})
+
+	level := LevelFromSlog(r.Level)
+	h.logger.print(ts, level, levelLabel(level), levelColor(level), []any{r.Message}, fields)
+	return nil
+}
+
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := slices.Clone(h.attrs)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.group, a)
+	}
+	return &ConsoleHandler{logger: h.logger, attrs: fields, group: h.group}
+}
+
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &ConsoleHandler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// appendAttr appends a to fields. Groups are flattened to dotted keys.
+func appendAttr(fields []field, prefix string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := prefix
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, group, ga)
+		}
+		return fields
+	}
+	return append(fields, field{key: prefix + a.Key, value: a.Value.Any()})
+}
+
+// remote
+
+// RLogHandler is a slog.Handler forwarding records to the destinations of an
+// RLog. Attributes are added to the json payload, groups become nested
+// objects. Keys and CommonData of the RLog are respected.
+type RLogHandler struct {
+	rlog   *RLog
+	attrs  map[string]any
+	groups []string
+}
+
+func NewRLogHandler(rlog *RLog) *RLogHandler {
+	return &RLogHandler{rlog: rlog, attrs: map[string]any{}}
+}
+
+func (h *RLogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.rlog.enabled(LevelFromSlog(level))
+}
+
+func (h *RLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	data := cloneMap(h.attrs)
+	target := groupMap(data, h.groups)
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(target, a)
+		return true
+	})
+
+	data = contextData(ctx, data)
+	h.rlog.emit(Entry{Time: ts, Level: LevelFromSlog(r.Level), Message: r.Message, Data: data}, false)
+	return nil
+}
+
+func (h *RLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	data := cloneMap(h.attrs)
+	target := groupMap(data, h.groups)
+	for _, a := range attrs {
+		addAttr(target, a)
+	}
+	return &RLogHandler{rlog: h.rlog, attrs: data, groups: h.groups}
+}
+
+func (h *RLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := append(append([]string{}, h.groups...), name)
+	return &RLogHandler{rlog: h.rlog, attrs: h.attrs, groups: groups}
+}
+
+// addAttr adds a to data. Groups become nested maps.
+func addAttr(data map[string]any, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		data[a.Key] = a.Value.Any()
+		return
+	}
+
+	target := data
+	if a.Key != "" {
+		target = groupMap(data, []string{a.Key})
+	}
+	for _, ga := range a.Value.Group() {
+		addAttr(target, ga)
+	}
+}
+
+// groupMap returns the nested map for groups, creating missing levels.
+func groupMap(data map[string]any, groups []string) map[string]any {
+	for _, g := range groups {
+		next, ok := data[g].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			data[g] = next
+		}
+		data = next
+	}
+	return data
+}
+
+func cloneMap(data map[string]any) map[string]any {
+	c := maps.Clone(data)
+	if c == nil {
+		c = map[string]any{}
+	}
+	for k, v := range c {
+		if m, ok := v.(map[string]any); ok {
+			c[k] = cloneMap(m)
+		}
+	}
+	return c
+}
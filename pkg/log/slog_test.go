@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	t.Setenv("LOG_IGNORE_COLOR", "true")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewConsoleHandler(NewLogger(&buf, LLInfo)))
+
+	logger.Debug("dropped")
+	logger.With("app", "test").WithGroup("req").Info("hello", "id", 1, slog.Group("user", "name", "a b"))
+	if !strings.HasSuffix(buf.String(), ` INFO: hello app=test req.id=1 req.user.name="a b"`+"\n") {
+		t.Error("TestConsoleHandler:: unexpected output", buf.String())
+	}
+}
+
+func TestRLogHandler(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan map[string]any, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var m map[string]any
+		if json.NewDecoder(conn).Decode(&m) == nil {
+			received <- m
+		}
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	rlog := NewRLog("127.0.0.1", port, "info", "", true)
+	rlog.UpdateCommonData(map[string]interface{}{"service": "svc"})
+	rlog.UpdateKeys("msg", "", "")
+
+	logger := slog.New(NewRLogHandler(rlog))
+	logger.Debug("dropped")
+	logger.WithGroup("req").Warn("hello", "id", 1)
+	rlog.Close()
+
+	select {
+	case m := <-received:
+		req, _ := m["req"].(map[string]any)
+		if m["msg"] != "hello" || m["severity"] != "WARN" || m["service"] != "svc" || req["id"] != 1.0 {
+			t.Error("TestRLogHandler:: unexpected payload", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("TestRLogHandler:: nothing received")
+	}
+}
@@ -0,0 +1,193 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// backoff
+
+// BackoffConfig configures the delay between reconnect attempts of an RLog.
+// The delay starts at Initial, doubles after every failed attempt up to Max
+// and is randomized by +/- Jitter (0..1).
+type BackoffConfig struct {
+	Initial time.Duration
+	Max     time.Duration
+	Jitter  float64
+}
+
+func DefaultBackoffConfig() BackoffConfig {
+	return BackoffConfig{Initial: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: 0.2}
+}
+
+type backoff struct {
+	config  BackoffConfig
+	current time.Duration
+	next    time.Time
+}
+
+// ready reports if the next attempt is due.
+func (b *backoff) ready() bool {
+	return !time.Now().Before(b.next)
+}
+
+// fail schedules the next attempt.
+func (b *backoff) fail() {
+	def := DefaultBackoffConfig()
+	if b.config.Initial <= 0 {
+		b.config.Initial = def.Initial
+	}
+	if b.config.Max <= 0 {
+		b.config.Max = def.Max
+	}
+
+	if b.current == 0 {
+		b.current = b.config.Initial
+	} else {
+		b.current = min(2*b.current, b.config.Max)
+	}
+
+	delay := b.current
+	if b.config.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * b.config.Jitter * float64(delay))
+	}
+	b.next = time.Now().Add(delay)
+}
+
+func (b *backoff) reset() {
+	b.current = 0
+	b.next = time.Time{}
+}
+
+// spill
+
+// SpillConfig configures the on-disk buffer of an RLog. Entries which cannot
+// be delivered are appended to Path and replayed in order once the endpoint
+// is reachable again. If the file would exceed MaxBytes the oldest entries
+// are dropped. Spilling is disabled if Path is empty.
+type SpillConfig struct {
+	Path     string
+	MaxBytes int64
+}
+
+// spillFile stores entries as length prefixed records. It is not safe for
+// concurrent use, the queue worker is the only user.
+type spillFile struct {
+	config SpillConfig
+}
+
+var errSpilled = errors.New("entries spilled to disk")
+
+// maxSpillRecord caps the length of a record. Longer entries are not spilled
+// and a longer length prefix marks the rest of the file as corrupt.
+const maxSpillRecord = 1 << 24
+
+func (s *spillFile) size() int64 {
+	info, err := os.Stat(s.config.Path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// append adds entries and returns the number of old entries dropped to stay
+// below the size cap.
+func (s *spillFile) append(entries [][]byte) (int, error) {
+	var add int64
+	for _, e := range entries {
+		add += int64(4 + len(e))
+	}
+
+	dropped := 0
+	if s.config.MaxBytes > 0 && s.size()+add > s.config.MaxBytes {
+		// trim to 3/4 of the cap to not rewrite the file on every append
+		all, err := s.read()
+		if err != nil {
+			return 0, err
+		}
+		all = append(all, entries...)
+		var size int64
+		for _, e := range all {
+			size += int64(4 + len(e))
+		}
+		for len(all) > 0 && size > s.config.MaxBytes*3/4 {
+			size -= int64(4 + len(all[0]))
+			all = all[1:]
+			dropped++
+		}
+		return dropped, s.write(all, os.O_TRUNC)
+	}
+	return dropped, s.write(entries, os.O_APPEND)
+}
+
+// read returns all stored entries in order.
+func (s *spillFile) read() ([][]byte, error) {
+	file, err := os.Open(s.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	entries := [][]byte{}
+	var head [4]byte
+	for {
+		if _, err := io.ReadFull(reader, head[:]); err != nil {
+			// ignore partially written records at the end
+			return entries, nil
+		}
+		n := binary.BigEndian.Uint32(head[:])
+		if n > maxSpillRecord || (s.config.MaxBytes > 0 && int64(n) > s.config.MaxBytes) {
+			// corrupt, ignore the rest
+			return entries, nil
+		}
+		entry := make([]byte, n)
+		if _, err := io.ReadFull(reader, entry); err != nil {
+			return entries, nil
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// replace stores entries as the only content.
+func (s *spillFile) replace(entries [][]byte) error {
+	if len(entries) == 0 {
+		err := os.Remove(s.config.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return s.write(entries, os.O_TRUNC)
+}
+
+func (s *spillFile) write(entries [][]byte, flag int) error {
+	file, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_WRONLY|flag, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	var head [4]byte
+	for _, e := range entries {
+		if len(e) > maxSpillRecord {
+			continue
+		}
+		binary.BigEndian.PutUint32(head[:], uint32(len(e)))
+		writer.Write(head[:])
+		writer.Write(e)
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSpillFileCap(t *testing.T) {
+	s := &spillFile{config: SpillConfig{Path: filepath.Join(t.TempDir(), "spill"), MaxBytes: 40}}
+
+	for i := range 10 {
+		if _, err := s.append([][]byte{[]byte("entry" + strconv.Itoa(i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.size() > 40 {
+		t.Error("TestSpillFileCap:: file exceeds cap", s.size())
+	}
+
+	entries, err := s.read()
+	if err != nil || len(entries) == 0 || string(entries[len(entries)-1]) != "entry9" {
+		t.Error("TestSpillFileCap:: should keep newest entries", entries, err)
+	}
+
+	s.replace(nil)
+	if s.size() != 0 {
+		t.Error("TestSpillFileCap:: replace should clear file")
+	}
+}
+
+func TestSpillFileCorrupt(t *testing.T) {
+	s := &spillFile{config: SpillConfig{Path: filepath.Join(t.TempDir(), "spill")}}
+	s.append([][]byte{[]byte("one")})
+
+	// length prefix beyond the cap followed by a valid record
+	file, err := os.OpenFile(s.config.Path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	file.Write([]byte{0, 0, 0, 3, 't', 'w', 'o'})
+	file.Close()
+
+	entries, err := s.read()
+	if err != nil || len(entries) != 1 || string(entries[0]) != "one" {
+		t.Error("TestSpillFileCorrupt:: rest of file should be ignored", entries, err)
+	}
+}
+
+func TestRLogSpillAndReplay(t *testing.T) {
+	// reserve port without listening
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	rlog := NewRLog("127.0.0.1", port, "info", "", true)
+	rlog.Queue.FlushInterval = 20 * time.Millisecond
+	rlog.Backoff = BackoffConfig{Initial: 10 * time.Millisecond, Max: 20 * time.Millisecond}
+	rlog.Spill = SpillConfig{Path: filepath.Join(t.TempDir(), "spill"), MaxBytes: 1 << 20}
+	defer rlog.Close()
+
+	rlog.Info("one")
+	rlog.Info("two")
+	rlog.Flush()
+	if s := rlog.Stats(); s.Spilled != 2 || s.Sent != 0 {
+		t.Fatal("TestRLogSpillAndReplay:: entries should be spilled", s)
+	}
+
+	// collector comes back
+	ln, err = net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		t.Skip("port not available anymore", err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 3)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		decoder := json.NewDecoder(conn)
+		for {
+			var m map[string]any
+			if decoder.Decode(&m) != nil {
+				return
+			}
+			received <- m["message"].(string)
+		}
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	rlog.Info("three")
+
+	for _, want := range []string{"one", "two", "three"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Error("TestRLogSpillAndReplay:: unexpected order, want", want, "got", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("TestRLogSpillAndReplay:: missing", want)
+		}
+	}
+	rlog.Flush()
+	if s := rlog.Stats(); s.Sent != 3 {
+		t.Error("TestRLogSpillAndReplay:: unexpected stats", s)
+	}
+}
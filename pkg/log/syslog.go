@@ -0,0 +1,207 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// format
+
+type Format int
+
+const (
+	FormatJson Format = iota
+	FormatSyslog5424
+	FormatSyslog3164
+)
+
+func GetFormat(format string) Format {
+	switch strings.ToLower(format) {
+	case "syslog", "rfc5424":
+		return FormatSyslog5424
+	case "rfc3164", "bsd":
+		return FormatSyslog3164
+	}
+	return FormatJson
+}
+
+// facility
+
+type Facility int
+
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+)
+
+const (
+	FacilityLocal0 Facility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogConfig configures the header of syslog formatted entries. Empty
+// values are replaced by defaults derived from the running process.
+type SyslogConfig struct {
+	Facility Facility
+	Hostname string
+	AppName  string
+	ProcId   string
+	MsgId    string
+	// SD-ID of the structured data element holding CommonData and the
+	// data map of the call (RFC 5424 only).
+	StructuredDataId string
+}
+
+func DefaultSyslogConfig() SyslogConfig {
+	return SyslogConfig{Facility: FacilityUser, StructuredDataId: "rlog@32473"}
+}
+
+// syslogSeverity maps log levels to RFC 5424 severities.
+func syslogSeverity(level LogLevel) int {
+	switch level {
+	case LLCritical:
+		return 2
+	case LLError:
+		return 3
+	case LLWarn:
+		return 4
+	case LLInfo:
+		return 6
+	}
+	return 7
+}
+
+// encodeSyslog formats an entry as RFC 5424 or RFC 3164 message.
+func (l *RLog) encodeSyslog(ts time.Time, level LogLevel, msg string, data map[string]interface{}) []byte {
+	c := l.Syslog
+	hostname := nilValue(c.Hostname, hostname())
+	appName := nilValue(c.AppName, filepath.Base(os.Args[0]))
+	procId := nilValue(c.ProcId, strconv.Itoa(os.Getpid()))
+	pri := int(c.Facility)*8 + syslogSeverity(level)
+
+	if l.IsUtc {
+		ts = ts.UTC()
+	}
+
+	if l.Format == FormatSyslog3164 {
+		line := fmt.Sprintf("<%d>%s %s %s[%s]: %s", pri, ts.Format(time.Stamp), hostname, appName, procId, msg)
+		if len(data) > 0 {
+			line += " " + formatFields(data)
+		}
+		return []byte(line)
+	}
+
+	line := fmt.Sprintf("<%d>1 %s %s %s %s %s %s", pri, ts.Format("2006-01-02T15:04:05.000000Z07:00"),
+		truncate(hostname, 255), truncate(appName, 48), truncate(procId, 128), truncate(nilValue(c.MsgId, "-"), 32),
+		structuredData(nilValue(c.StructuredDataId, DefaultSyslogConfig().StructuredDataId), data))
+	if msg != "" {
+		line += " " + msg
+	}
+	return []byte(line)
+}
+
+// structuredData renders data as a single SD-ELEMENT, nested maps are
+// flattened to dotted param names.
+func structuredData(id string, data map[string]interface{}) string {
+	params := map[string]string{}
+	flattenParams(params, "", data)
+	if len(params) == 0 {
+		return "-"
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + id)
+	for _, k := range slices.Sorted(maps.Keys(params)) {
+		b.WriteString(" " + k + `="` + params[k] + `"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func flattenParams(params map[string]string, prefix string, data map[string]interface{}) {
+	for k, v := range data {
+		name := prefix + k
+		if m, ok := v.(map[string]interface{}); ok {
+			flattenParams(params, name+".", m)
+			continue
+		}
+		params[truncate(paramName(name), 32)] = paramValue(v)
+	}
+}
+
+// paramName drops characters not allowed in SD-NAMEs.
+func paramName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= 32 || r >= 127 || r == '=' || r == ']' || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// paramValue escapes '"', '\' and ']' as required for PARAM-VALUEs.
+func paramValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case fmt.Stringer, error, bool, int, int64, uint64, float64:
+		s = fmt.Sprint(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprint(v)
+		} else {
+			s = string(b)
+		}
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+func nilValue(value, fallback string) string {
+	if value == "" {
+		value = fallback
+	}
+	if value == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(value, " ", "_")
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
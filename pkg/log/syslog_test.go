@@ -0,0 +1,66 @@
+package log
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeSyslog(t *testing.T) {
+	rlog := NewRLog("", 0, "verbose", "", true)
+	rlog.Syslog = SyslogConfig{Facility: FacilityLocal0, Hostname: "host", AppName: "app", ProcId: "42", MsgId: "ID1"}
+	rlog.Format = FormatSyslog5424
+	ts := time.Date(2024, 3, 1, 12, 30, 15, 123456000, time.UTC)
+
+	got := string(rlog.encodeSyslog(ts, LLWarn, "hello", map[string]interface{}{"env": "prod", "q": `a"b]`}))
+	want := `<132>1 2024-03-01T12:30:15.123456Z host app 42 ID1 [rlog@32473 env="prod" q="a\"b\]"] hello`
+	if got != want {
+		t.Errorf("TestEncodeSyslog:: rfc5424\nwant %s\ngot  %s", want, got)
+	}
+
+	got = string(rlog.encodeSyslog(ts, LLDebug, "hello", nil))
+	want = `<135>1 2024-03-01T12:30:15.123456Z host app 42 ID1 - hello`
+	if got != want {
+		t.Errorf("TestEncodeSyslog:: rfc5424 without data\nwant %s\ngot  %s", want, got)
+	}
+
+	rlog.Format = FormatSyslog3164
+	got = string(rlog.encodeSyslog(ts, LLError, "hello", map[string]interface{}{"env": "prod"}))
+	want = `<131>Mar  1 12:30:15 host app[42]: hello env=prod`
+	if got != want {
+		t.Errorf("TestEncodeSyslog:: rfc3164\nwant %s\ngot  %s", want, got)
+	}
+}
+
+func TestRLogSyslogUnixgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skip("unixgram not supported", err)
+	}
+	defer conn.Close()
+
+	rlog := NewRLog("", 0, "info", "", true)
+	rlog.Address = path
+	rlog.Protocol = Unixgram
+	rlog.Format = FormatSyslog5424
+	rlog.Framing = FramingNone
+	rlog.Syslog.Hostname = "host"
+	rlog.Syslog.AppName = "app"
+	rlog.Syslog.ProcId = "1"
+	rlog.Error("boom")
+	rlog.Close()
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal("TestRLogSyslogUnixgram:: nothing received", err)
+	}
+	got := string(buf[:n])
+	if !strings.HasPrefix(got, "<11>1 ") || !strings.HasSuffix(got, " host app 1 - - boom") {
+		t.Error("TestRLogSyslogUnixgram:: unexpected message", got)
+	}
+}
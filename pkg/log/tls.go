@@ -0,0 +1,52 @@
+package log
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+// TlsConfig configures the Tls protocol of an RLog. Setting CertFile and
+// KeyFile enables mutual TLS.
+type TlsConfig struct {
+	CAFile             string // pem bundle to verify the server, system pool if empty
+	CertFile           string // client certificate
+	KeyFile            string // client key
+	ServerName         string // overrides the host name used for verification
+	MinVersion         uint16 // e.g. tls.VersionTLS13, defaults to tls.VersionTLS12
+	InsecureSkipVerify bool
+}
+
+// Config returns the tls config to dial with.
+func (c TlsConfig) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		MinVersion:         c.MinVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
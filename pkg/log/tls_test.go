@@ -0,0 +1,153 @@
+package log
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRLogMutualTls(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := createCert(t, dir, "ca", nil, nil)
+	server, _ := createCert(t, dir, "server", ca, caKey)
+	createCert(t, dir, "client", ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type result struct {
+		client string
+		line   string
+	}
+	received := make(chan result, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		scanner := bufio.NewScanner(tlsConn)
+		if scanner.Scan() {
+			received <- result{client: tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName, line: scanner.Text()}
+		}
+	}()
+
+	rlog := NewRLogExt("127.0.0.1", ln.Addr().(*net.TCPAddr).Port, "info", "", true, Tls, 3)
+	rlog.Tls = TlsConfig{
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		CertFile:   filepath.Join(dir, "client.pem"),
+		KeyFile:    filepath.Join(dir, "client.key"),
+		ServerName: server.Subject.CommonName,
+		MinVersion: tls.VersionTLS13,
+	}
+	rlog.Info("secret")
+	rlog.Close()
+
+	select {
+	case r := <-received:
+		if r.client != "client" || !strings.Contains(r.line, `"message":"secret"`) {
+			t.Error("TestRLogMutualTls:: unexpected result", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("TestRLogMutualTls:: nothing received", rlog.Stats())
+	}
+}
+
+func TestTlsConfigErrors(t *testing.T) {
+	if _, err := (TlsConfig{CAFile: "does-not-exist.pem"}).Config(); err == nil {
+		t.Error("TestTlsConfigErrors:: missing ca file should fail")
+	}
+	if _, err := (TlsConfig{CertFile: "does-not-exist.pem"}).Config(); err == nil {
+		t.Error("TestTlsConfigErrors:: missing key pair should fail")
+	}
+	config, err := TlsConfig{}.Config()
+	if err != nil || config.MinVersion != tls.VersionTLS12 {
+		t.Error("TestTlsConfigErrors:: unexpected default config", err)
+	}
+}
+
+func TestRLogInvalidTls(t *testing.T) {
+	rlog := NewRLogExt("127.0.0.1", 6514, "info", "", true, Tls, 1)
+	rlog.Tls = TlsConfig{CAFile: "does-not-exist.pem"}
+	rlog.Spill = SpillConfig{Path: filepath.Join(t.TempDir(), "spill")}
+	defer rlog.Close()
+
+	if err := rlog.Start(); err == nil {
+		t.Error("TestRLogInvalidTls:: expected config error")
+	}
+	rlog.Info("lost")
+	rlog.Flush()
+	if s := rlog.Stats(); s.Failed != 1 || s.Spilled != 0 || s.Retries != 0 {
+		t.Error("TestRLogInvalidTls:: entry should fail without queueing", s)
+	}
+}
+
+// createCert writes <name>.pem and <name>.key to dir. The cert is self signed
+// if parent is nil.
+func createCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{name},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
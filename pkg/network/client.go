@@ -1,11 +1,14 @@
 package network
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/nice-pink/goutil/pkg/log"
 )
@@ -14,13 +17,26 @@ type Headers map[string]string
 
 type AuthFn func() (string, error)
 
+// Client is safe for concurrent use. Configure it before sending requests.
 type Client struct {
 	verbose       bool
 	sharedHeaders Headers
-	token         string
 	basicAuth     string
 	authFn        AuthFn
+	source        TokenSource
 	httpClient    *http.Client
+	retry         RetryPolicy
+
+	mu      sync.Mutex // guards token and refresh
+	token   string
+	refresh *tokenCall // refresh in progress
+}
+
+// tokenCall is a token refresh other requests wait for.
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
 }
 
 // sharedHeaders: will be added to all requests
@@ -35,87 +51,219 @@ func NewClient(sharedHeaders Headers, token, basicAuth string, authFn AuthFn, ve
 		basicAuth:     basicAuth,
 		httpClient:    &http.Client{},
 		authFn:        authFn,
+		retry:         DefaultRetryPolicy(),
 	}
 }
 
+// SetTimeout limits the duration of each attempt of a request including
+// reading the body, 0 disables the timeout. Use a context to limit a request
+// including retries.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// SetRetryPolicy replaces DefaultRetryPolicy used by NewClient.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetTokenSource fetches tokens from source instead of the auth function.
+// Each request requiring auth gets its token from the source or from a lookup
+// already in flight, so the source can refresh tokens before they expire.
+func (c *Client) SetTokenSource(source TokenSource) {
+	c.source = source
+}
+
 func (c *Client) ClearToken() {
-	c.token = ""
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clearToken(c.token)
 }
 
+// RefreshToken fetches a token if none is set. Concurrent calls wait for a
+// single fetch.
 func (c *Client) RefreshToken() error {
+	_, err := c.refreshToken(context.Background())
+	return err
+}
+
+// token
+
+// refreshToken returns the token to use. Only one fetch runs at a time,
+// other callers wait for its result or until ctx is done.
+func (c *Client) refreshToken(ctx context.Context) (string, error) {
 	if c.basicAuth != "" {
 		// use basic auth
-		return nil
+		return "", nil
 	}
 
-	// use token
-	if c.authFn == nil && c.token == "" {
-		// token can't be generated
-		return errors.New("no auth function")
+	for {
+		c.mu.Lock()
+		if c.source == nil {
+			if c.token != "" {
+				// has token
+				token := c.token
+				c.mu.Unlock()
+				return token, nil
+			}
+			if c.authFn == nil {
+				// token can't be generated
+				c.mu.Unlock()
+				return "", errors.New("no auth function")
+			}
+		}
+
+		call := c.refresh
+		if call == nil {
+			// fetch
+			call = &tokenCall{done: make(chan struct{}), err: errors.New("token refresh panicked")}
+			c.refresh = call
+			c.mu.Unlock()
+			return c.runRefresh(ctx, call)
+		}
+		c.mu.Unlock()
+
+		// wait
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		if call.err != nil && ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+			// cancelled by the fetching request, try again
+			continue
+		}
+		return call.token, call.err
 	}
+}
 
-	if c.token != "" {
-		// has token
-		return nil
+// runRefresh fetches the token for call. Waiters are released even if the
+// auth function panics.
+func (c *Client) runRefresh(ctx context.Context, call *tokenCall) (string, error) {
+	defer func() {
+		c.mu.Lock()
+		if call.err == nil {
+			c.token = call.token
+		}
+		c.refresh = nil
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	call.token, call.err = c.fetchToken(ctx)
+	return call.token, call.err
+}
+
+func (c *Client) fetchToken(ctx context.Context) (string, error) {
+	if c.source != nil {
+		token, err := c.source.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
 	}
+	return c.authFn()
+}
 
-	// get token
-	token, err := c.authFn()
-	if err != nil {
-		return err
+// clearToken drops token if it is still in use, so requests rejected with
+// the same token trigger a single refresh. c.mu must be held.
+func (c *Client) clearToken(token string) {
+	if token == "" || token != c.token {
+		return
 	}
-	c.token = token
-	return nil
+	if inv, ok := c.source.(interface{ Invalidate(string) }); ok {
+		inv.Invalidate(token)
+	}
+	c.token = ""
 }
 
 // request
 
 func (c *Client) Request(method, url string, body io.Reader, headers Headers, authRequired bool) (*http.Response, error) {
+	return c.RequestCtx(context.Background(), method, url, body, headers, authRequired)
+}
+
+// RequestCtx sends the request and retries according to the retry policy.
+// On 401 the token is fetched again up to MaxReauth times if an auth
+// function is set. WithRetryPolicy overrides the policy per request.
+// Bodies are buffered to be replayed if needed. Cancelling ctx aborts the
+// request and pending retries.
+func (c *Client) RequestCtx(ctx context.Context, method, url string, body io.Reader, headers Headers, authRequired bool) (*http.Response, error) {
 	if c.verbose {
 		log.Verbose(strings.ToUpper(method), url)
 	}
 
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		log.Err(err, "Could not create request.", method, url)
 		return nil, err
 	}
 
-	if authRequired {
-		err := c.RefreshToken()
-		if err != nil {
+	policy := policyFor(ctx, c.retry)
+	canRetry := policy.canRetry(req.Method, headers)
+	if canRetry || (authRequired && policy.MaxReauth > 0 && c.canReauth()) {
+		if err := rewindable(req); err != nil {
+			log.Err(err, "Could not read request body.", method, url)
 			return nil, err
 		}
 	}
 
-	// set headers
-	c.addHeaders(req, headers, authRequired)
+	attempt, reauth := 1, 0
+	for {
+		resp, token, err := c.send(req, headers, authRequired)
 
-	// request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		if c.verbose {
-			log.Err(err, "Could not send request.")
+		if err == nil && authRequired && resp.StatusCode == http.StatusUnauthorized && c.canReauth() && reauth < policy.MaxReauth {
+			if c.verbose {
+				log.Info("not authorized -> clear token.", req.URL, resp.StatusCode)
+			}
+			drain(resp)
+			c.mu.Lock()
+			c.clearToken(token)
+			c.mu.Unlock()
+			reauth++
+			continue
 		}
-		return nil, err
-	}
 
-	if authRequired && resp.StatusCode == http.StatusUnauthorized {
+		if ctx.Err() != nil {
+			if resp != nil {
+				drain(resp)
+			}
+			return nil, ctx.Err()
+		}
+
+		retry := canRetry && ((err != nil && policy.retryErr(err)) || (err == nil && policy.retryStatus(resp.StatusCode)))
+		if !retry || attempt >= policy.MaxAttempts {
+			if err != nil && c.verbose {
+				log.Err(err, "Could not send request.")
+			}
+			return resp, err
+		}
+
+		wait := policy.delay(attempt, retryAfter(resp))
 		if c.verbose {
-			log.Info("not authorized -> clear token.", req.URL, resp.StatusCode)
+			status := ""
+			if resp != nil {
+				status = resp.Status
+			}
+			log.Info("retry request in", wait, "attempt", attempt, "of", policy.MaxAttempts, status, err)
+		}
+		if resp != nil {
+			drain(resp)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
 		}
-		c.ClearToken()
-		// recursive call after clearing token
-		return c.Request(method, url, body, headers, authRequired)
+		attempt++
 	}
-
-	return resp, nil
 }
 
 // convenience
 
 func (c *Client) RequestData(method, url string, body io.Reader, headers Headers, authRequired bool) ([]byte, error) {
-	resp, err := c.Request(method, url, body, headers, authRequired)
+	return c.RequestDataCtx(context.Background(), method, url, body, headers, authRequired)
+}
+
+func (c *Client) RequestDataCtx(ctx context.Context, method, url string, body io.Reader, headers Headers, authRequired bool) ([]byte, error) {
+	resp, err := c.RequestCtx(ctx, method, url, body, headers, authRequired)
 	if err != nil {
 		if c.verbose {
 			log.Err(err, "response error", url)
@@ -137,53 +285,112 @@ func (c *Client) RequestData(method, url string, body io.Reader, headers Headers
 }
 
 func (c *Client) RequestMap(method, url string, body io.Reader, headers Headers, authRequired bool) (map[string]any, error) {
-	data, err := c.RequestData(method, url, body, headers, authRequired)
-	if err != nil {
-		if c.verbose {
-			log.Err(err, "response error", url)
-		}
-		return nil, err
-	}
+	return c.RequestMapCtx(context.Background(), method, url, body, headers, authRequired)
+}
 
-	// unmarshal to map
+func (c *Client) RequestMapCtx(ctx context.Context, method, url string, body io.Reader, headers Headers, authRequired bool) (map[string]any, error) {
 	var m map[string]any
-	err = json.Unmarshal(data, &m)
-	if err != nil {
-		if c.verbose {
-			log.Err(err, "unmarshal error", url, string(data))
-		}
+	if err := c.RequestTypeCtx(ctx, method, url, body, headers, authRequired, &m); err != nil {
 		return nil, err
 	}
-	return m, err
+	return m, nil
 }
 
 func (c *Client) RequestType(method, url string, body io.Reader, headers Headers, authRequired bool, output any) error {
-	data, err := c.RequestData(method, url, body, headers, authRequired)
+	return c.RequestTypeCtx(context.Background(), method, url, body, headers, authRequired, output)
+}
+
+// RequestTypeCtx decodes the json response into output, which must be a
+// pointer. Responses with status codes other than 2xx return *HTTPError.
+func (c *Client) RequestTypeCtx(ctx context.Context, method, url string, body io.Reader, headers Headers, authRequired bool, output any) error {
+	resp, err := c.RequestCtx(ctx, method, url, body, headers, authRequired)
 	if err != nil {
 		if c.verbose {
 			log.Err(err, "response error", url)
 		}
 		return err
 	}
+	defer resp.Body.Close()
 
-	// unmarshal to map
-	err = json.Unmarshal(data, &output)
-	if err != nil {
+	if err := checkStatus(resp); err != nil {
 		if c.verbose {
-			log.Err(err, "unmarshal error", url, string(data))
+			log.Err(err, "response error", url)
 		}
 		return err
 	}
-	return err
+
+	// decode
+	if err := decode(resp.Body, output); err != nil {
+		if c.verbose {
+			log.Err(err, "unmarshal error", url)
+		}
+		return err
+	}
+	return nil
 }
 
 // intern
 
-func (c *Client) addHeaders(req *http.Request, headers Headers, authRequired bool) {
+// hasAuth reports if a token, basic auth, auth function or token source is
+// configured.
+func (c *Client) hasAuth() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token != "" || c.basicAuth != "" || c.canReauth()
+}
+
+// canReauth reports if a new token can be fetched.
+func (c *Client) canReauth() bool {
+	return c.authFn != nil || c.source != nil
+}
+
+// send sends a copy of req with a fresh body and the current auth headers.
+// The token sent is returned.
+func (c *Client) send(req *http.Request, headers Headers, authRequired bool) (*http.Response, string, error) {
+	token := ""
+	if authRequired {
+		var err error
+		if token, err = c.refreshToken(req.Context()); err != nil {
+			return nil, "", err
+		}
+	}
+
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, token, err
+		}
+		r.Body = body
+	}
+	c.addHeaders(r, headers, token, authRequired)
+	resp, err := c.httpClient.Do(r)
+	return resp, token, err
+}
+
+// rewindable buffers the body of req, unless it can be recreated already.
+func rewindable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+func (c *Client) addHeaders(req *http.Request, headers Headers, token string, authRequired bool) {
 	// bearer token
 	if authRequired {
-		if c.token != "" {
-			req.Header.Add("Authorization", "Bearer "+c.token)
+		if token != "" {
+			req.Header.Add("Authorization", "Bearer "+token)
 		}
 		if c.basicAuth != "" {
 			req.Header.Add("Authorization", "Basic "+c.basicAuth)
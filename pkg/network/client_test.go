@@ -0,0 +1,455 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.Backoff = time.Millisecond
+	policy.MaxBackoff = 50 * time.Millisecond
+	return policy
+}
+
+func TestRequestRetry(t *testing.T) {
+	var calls atomic.Int32
+	bodies := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	c := NewClient(nil, "", "", nil, false)
+	c.SetRetryPolicy(testPolicy())
+	// not rewindable by http.NewRequest
+	body := io.MultiReader(strings.NewReader("pay"), strings.NewReader("load"))
+	data, err := c.RequestData(http.MethodPut, server.URL, body, nil, false)
+	if err != nil || string(data) != "ok" || calls.Load() != 3 {
+		t.Error("TestRequestRetry:: expected success after 3 calls", calls.Load(), string(data), err)
+	}
+	close(bodies)
+	for b := range bodies {
+		if b != "payload" {
+			t.Error("TestRequestRetry:: body not replayed", b)
+		}
+	}
+}
+
+func TestRequestRetryIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	c := NewClient(nil, "", "", nil, false)
+	c.SetRetryPolicy(testPolicy())
+
+	// streamed, the body is completed after the request arrived
+	body, writer := io.Pipe()
+	go func() {
+		select {
+		case <-started:
+			writer.Write([]byte("payload"))
+			writer.Close()
+		case <-time.After(time.Second):
+			writer.CloseWithError(errors.New("body was buffered"))
+		}
+	}()
+	if _, err := c.Request(http.MethodPost, server.URL, body, nil, false); err != nil || calls.Load() != 1 {
+		t.Error("TestRequestRetryIdempotent:: post should be streamed and sent once", calls.Load(), err)
+	}
+
+	// idempotency key
+	calls.Store(0)
+	c.Request(http.MethodPost, server.URL, strings.NewReader("payload"), Headers{"Idempotency-Key": "1"}, false)
+	if calls.Load() != 3 {
+		t.Error("TestRequestRetryIdempotent:: post with idempotency key should be retried", calls.Load())
+	}
+
+	// opt in
+	calls.Store(0)
+	policy := testPolicy()
+	policy.RetryNonIdempotent = true
+	c.SetRetryPolicy(policy)
+	c.Request(http.MethodPatch, server.URL, strings.NewReader("payload"), nil, false)
+	if calls.Load() != 3 {
+		t.Error("TestRequestRetryIdempotent:: patch should be retried", calls.Load())
+	}
+}
+
+func TestRequestRetryExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	c := NewClient(nil, "", "", nil, false)
+	c.SetRetryPolicy(testPolicy())
+	resp, err := c.Request(http.MethodGet, server.URL, nil, nil, false)
+	if err != nil || resp.StatusCode != http.StatusBadGateway || calls.Load() != 3 {
+		t.Error("TestRequestRetryExhausted:: expected last response after 3 calls", calls.Load(), err)
+	}
+
+	// policy of the request
+	calls.Store(0)
+	c.RequestCtx(WithRetryPolicy(context.Background(), NoRetryPolicy()), http.MethodGet, server.URL, nil, nil, false)
+	if calls.Load() != 1 {
+		t.Error("TestRequestRetryExhausted:: request policy should be used", calls.Load())
+	}
+
+	// not retryable
+	calls.Store(0)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, RetryStatus: []int{http.StatusServiceUnavailable}})
+	c.Request(http.MethodGet, server.URL, nil, nil, false)
+	if calls.Load() != 1 {
+		t.Error("TestRequestRetryExhausted:: status should not be retried", calls.Load())
+	}
+}
+
+func TestRequestRetryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	c := NewClient(nil, "", "", nil, false)
+	c.SetRetryPolicy(testPolicy())
+	start := time.Now()
+	if _, err := c.Request(http.MethodGet, url, nil, nil, false); err == nil || !IsRetryableError(err) {
+		t.Error("TestRequestRetryError:: expected refused connection", err)
+	}
+	if time.Since(start) < 2*time.Millisecond {
+		t.Error("TestRequestRetryError:: expected backoff between attempts")
+	}
+}
+
+func TestRequestReauth(t *testing.T) {
+	var calls, tokens atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	// expired token is replaced once
+	c := NewClient(nil, "expired", "", func() (string, error) {
+		tokens.Add(1)
+		return "valid", nil
+	}, false)
+	resp, err := c.Request(http.MethodGet, server.URL, nil, nil, true)
+	if err != nil || resp.StatusCode != http.StatusOK || tokens.Load() != 1 {
+		t.Error("TestRequestReauth:: expected success with new token", tokens.Load(), err)
+	}
+
+	// permanently bad token doesn't loop
+	calls.Store(0)
+	c = NewClient(nil, "", "", func() (string, error) { return "invalid", nil }, false)
+	resp, err = c.Request(http.MethodGet, server.URL, nil, nil, true)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || calls.Load() != 2 {
+		t.Error("TestRequestReauth:: expected 401 after one re-auth", calls.Load(), err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	if policy.delay(1, 0) != time.Second || policy.delay(3, 0) != 4*time.Second || policy.delay(10, 0) != 5*time.Second {
+		t.Error("TestRetryDelay:: unexpected backoff")
+	}
+	if policy.delay(1, 2*time.Second) != 2*time.Second || policy.delay(1, time.Minute) != 5*time.Second {
+		t.Error("TestRetryDelay:: retry after should replace backoff and be capped")
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"7"}}}
+	if retryAfter(resp) != 7*time.Second {
+		t.Error("TestRetryDelay:: unexpected retry after seconds")
+	}
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if d := retryAfter(resp); d < 59*time.Minute || d > time.Hour {
+		t.Error("TestRetryDelay:: unexpected retry after date", d)
+	}
+}
+
+func TestRequestCtx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := NewClient(nil, "", "", nil, false)
+	policy := testPolicy()
+	policy.MaxBackoff = time.Minute
+	c.SetRetryPolicy(policy)
+
+	// in flight
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.RequestDataCtx(ctx, http.MethodGet, server.URL+"/slow", nil, nil, false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("TestRequestCtx:: expected deadline exceeded", err)
+	}
+
+	// waiting for retry
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := c.RequestCtx(ctx, http.MethodGet, server.URL, nil, nil, false); !errors.Is(err, context.Canceled) {
+		t.Error("TestRequestCtx:: expected canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("TestRequestCtx:: retry wait should be cancelled")
+	}
+
+	// client timeout
+	c.SetTimeout(20 * time.Millisecond)
+	c.SetRetryPolicy(NoRetryPolicy())
+	if _, err := c.Request(http.MethodGet, server.URL+"/slow", nil, nil, false); err == nil {
+		t.Error("TestRequestCtx:: expected client timeout")
+	}
+}
+
+func TestDownloadHttpCtx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := DownloadHttpCtx(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("TestDownloadHttpCtx:: expected deadline exceeded", err)
+	}
+}
+
+func TestFileTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	prev := FileTimeout
+	defer func() { FileTimeout = prev }()
+	FileTimeout = 20 * time.Millisecond
+
+	start := time.Now()
+	if _, err := DownloadHttp(server.URL); err == nil {
+		t.Error("TestFileTimeout:: expected download timeout")
+	}
+	if err := UploadHttp(server.URL, "text/plain", []byte("data")); err == nil {
+		t.Error("TestFileTimeout:: expected upload timeout")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("TestFileTimeout:: timeout not applied", time.Since(start))
+	}
+}
+
+// rotatingServer accepts only Bearer token-<generation>.
+func rotatingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var generation atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-"+strconv.Itoa(int(generation.Load())) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &generation
+}
+
+// parallel sends n requests at once and reports failed ones.
+func parallel(t *testing.T, c *Client, url string, n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Request(http.MethodGet, url, nil, nil, true)
+			if err != nil {
+				t.Error("parallel:: request failed", err)
+				return
+			}
+			drain(resp)
+			if resp.StatusCode != http.StatusOK {
+				t.Error("parallel:: unexpected status", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRequestParallelReauth(t *testing.T) {
+	server, generation := rotatingServer(t)
+
+	var fetches, inFlight, maxInFlight atomic.Int32
+	c := NewClient(nil, "", "", func() (string, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if n > maxInFlight.Load() {
+			maxInFlight.Store(n)
+		}
+		fetches.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "token-" + strconv.Itoa(int(generation.Load())), nil
+	}, false)
+	c.SetRetryPolicy(NoRetryPolicy())
+
+	// tokens expire between rounds, each round refreshes once
+	for round := range 5 {
+		generation.Store(int32(round))
+		parallel(t, c, server.URL, 20)
+	}
+	if fetches.Load() != 5 || maxInFlight.Load() != 1 {
+		t.Error("TestRequestParallelReauth:: expected single flight refresh per round", fetches.Load(), maxInFlight.Load())
+	}
+}
+
+type countingSource struct {
+	generation *atomic.Int32
+	fetches    atomic.Int32
+}
+
+func (s *countingSource) Token(ctx context.Context) (*Token, error) {
+	s.fetches.Add(1)
+	return &Token{AccessToken: "token-" + strconv.Itoa(int(s.generation.Load()))}, nil
+}
+
+func TestRequestParallelTokenSource(t *testing.T) {
+	server, generation := rotatingServer(t)
+	source := &countingSource{generation: generation}
+	c := NewClient(nil, "", "", nil, false)
+	c.SetTokenSource(source)
+
+	// each request uses a lookup of its own or an in-flight one
+	for round := range 3 {
+		generation.Store(int32(round))
+		parallel(t, c, server.URL, 20)
+	}
+	if n := source.fetches.Load(); n <= 0 || n > 60 {
+		t.Error("TestRequestParallelTokenSource:: expected at most a lookup per request", n)
+	}
+
+	// racing clear and refresh
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.ClearToken()
+		}()
+		go func() {
+			defer wg.Done()
+			if err := c.RefreshToken(); err != nil {
+				t.Error("TestRequestParallelTokenSource:: refresh failed", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRefreshTokenWait(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	c := NewClient(nil, "", "", func() (string, error) {
+		fetches.Add(1)
+		<-release
+		return "token", nil
+	}, false)
+
+	done := make(chan error)
+	go func() { done <- c.RefreshToken() }()
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// waiting request is cancelled, fetch continues
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.refreshToken(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("TestRefreshTokenWait:: expected deadline exceeded", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil || fetches.Load() != 1 {
+		t.Error("TestRefreshTokenWait:: unexpected refresh", fetches.Load(), err)
+	}
+	if token, err := c.refreshToken(context.Background()); err != nil || token != "token" || fetches.Load() != 1 {
+		t.Error("TestRefreshTokenWait:: expected cached token", token, err)
+	}
+}
+
+func TestRefreshTokenPanic(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	c := NewClient(nil, "", "", func() (string, error) {
+		if fetches.Add(1) == 1 {
+			<-release
+			panic("auth failed")
+		}
+		return "token", nil
+	}, false)
+
+	go func() {
+		defer func() { recover() }()
+		c.RefreshToken()
+	}()
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// waiting request is released with an error
+	waiting := make(chan error)
+	go func() {
+		_, err := c.refreshToken(context.Background())
+		waiting <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	select {
+	case err := <-waiting:
+		if err == nil {
+			t.Error("TestRefreshTokenPanic:: expected error for waiting request")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TestRefreshTokenPanic:: waiting request blocked")
+	}
+
+	// next request fetches again
+	if token, err := c.refreshToken(context.Background()); err != nil || token != "token" {
+		t.Error("TestRefreshTokenPanic:: expected new token", token, err)
+	}
+}
@@ -0,0 +1,129 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// MaxErrorBody limits the body kept in an HTTPError.
+const MaxErrorBody = 4096
+
+// HTTPError is returned for responses with status codes other than 2xx.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Url        string
+	Header     http.Header
+	Body       []byte // truncated to MaxErrorBody
+}
+
+func (e *HTTPError) Error() string {
+	msg := e.Method + " " + e.Url + ": " + e.Status
+	if len(e.Body) > 0 {
+		msg += ": " + string(e.Body)
+	}
+	return msg
+}
+
+// Response holds status and headers of a decoded response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// Do sends a request and decodes the json response into T, see DoCtx.
+func Do[T any](c *Client, method, url string, body any) (T, *Response, error) {
+	return DoCtx[T](context.Background(), c, method, url, body, nil)
+}
+
+// DoCtx sends a request and decodes the json response into T. The response
+// is decoded while reading, an empty body results in the zero value. A
+// []byte T receives the raw body.
+//
+// body is sent as is if it is an io.Reader or []byte, otherwise it is
+// encoded as json. nil sends no body.
+//
+// Auth of the client is used if configured. Responses with status codes
+// other than 2xx return *HTTPError.
+func DoCtx[T any](ctx context.Context, c *Client, method, url string, body any, headers Headers) (T, *Response, error) {
+	var out T
+
+	reader, contentType, err := encodeBody(body)
+	if err != nil {
+		return out, nil, err
+	}
+	if contentType != "" {
+		h := Headers{"Content-Type": contentType}
+		for k, v := range headers {
+			h[k] = v
+		}
+		headers = h
+	}
+
+	resp, err := c.RequestCtx(ctx, method, url, reader, headers, c.hasAuth())
+	if err != nil {
+		return out, nil, err
+	}
+	defer resp.Body.Close()
+
+	r := &Response{StatusCode: resp.StatusCode, Header: resp.Header}
+	if err := checkStatus(resp); err != nil {
+		return out, r, err
+	}
+	if raw, ok := any(&out).(*[]byte); ok {
+		*raw, err = io.ReadAll(resp.Body)
+		return out, r, err
+	}
+	return out, r, decode(resp.Body, &out)
+}
+
+// intern
+
+func encodeBody(body any) (io.Reader, string, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, "", nil
+	case io.Reader:
+		return b, "", nil
+	case []byte:
+		return bytes.NewReader(b), "", nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(data), "application/json", nil
+}
+
+// checkStatus returns *HTTPError for status codes other than 2xx.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxErrorBody))
+	status := resp.Status
+	if status == "" {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	e := &HTTPError{StatusCode: resp.StatusCode, Status: status, Header: resp.Header, Body: body}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.Url = resp.Request.URL.String()
+	}
+	return e
+}
+
+// decode streams json from r into output. An empty body is no error.
+func decode(r io.Reader, output any) error {
+	err := json.NewDecoder(r).Decode(output)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var u user
+			json.NewDecoder(r.Body).Decode(&u)
+			u.Id = 7
+			w.Header().Set("Location", "/users/7")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(u)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/raw":
+			w.Write([]byte("plain text"))
+		default:
+			w.Header().Set("X-Reason", "missing")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(strings.Repeat("x", MaxErrorBody+10)))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(nil, "token", "", nil, false)
+	c.SetRetryPolicy(NoRetryPolicy())
+
+	u, resp, err := Do[user](c, http.MethodPost, server.URL+"/users", user{Name: "jane"})
+	if err != nil || u.Id != 7 || u.Name != "jane" || resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") != "/users/7" {
+		t.Error("TestDo:: unexpected result", u, resp, err)
+	}
+
+	empty, resp, err := Do[*user](c, http.MethodDelete, server.URL+"/empty", nil)
+	if err != nil || empty != nil || resp.StatusCode != http.StatusNoContent {
+		t.Error("TestDo:: empty body should decode to zero value", empty, err)
+	}
+
+	raw, _, err := Do[[]byte](c, http.MethodGet, server.URL+"/raw", nil)
+	if err != nil || string(raw) != "plain text" {
+		t.Error("TestDo:: expected raw body", string(raw), err)
+	}
+
+	_, resp, err = Do[user](c, http.MethodGet, server.URL+"/missing", nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound || resp.StatusCode != http.StatusNotFound {
+		t.Fatal("TestDo:: expected http error", err)
+	}
+	if len(httpErr.Body) != MaxErrorBody || httpErr.Header.Get("X-Reason") != "missing" || httpErr.Method != http.MethodGet {
+		t.Error("TestDo:: unexpected http error", httpErr.Method, httpErr.Header, len(httpErr.Body))
+	}
+}
+
+func TestRequestTypeStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			http.Error(w, `{"error":"broken"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"id":1,"name":"jane"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(nil, "", "", nil, false)
+	c.SetRetryPolicy(NoRetryPolicy())
+
+	var u user
+	if err := c.RequestType(http.MethodGet, server.URL, nil, nil, false, &u); err != nil || u.Name != "jane" {
+		t.Error("TestRequestTypeStatus:: unexpected result", u, err)
+	}
+	if err := c.RequestType(http.MethodGet, server.URL, nil, nil, false, u); err == nil {
+		t.Error("TestRequestTypeStatus:: non pointer output should fail")
+	}
+
+	m, err := c.RequestMap(http.MethodGet, server.URL+"/error", nil, nil, false)
+	var httpErr *HTTPError
+	if m != nil || !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Error("TestRequestTypeStatus:: expected http error", m, err)
+	}
+}
@@ -2,15 +2,26 @@ package network
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/nice-pink/goutil/pkg/log"
 )
 
+// FileTimeout limits each download and upload including the transfer of the
+// body, 0 disables the timeout. Use the Ctx variants to cancel a single
+// transfer.
+var FileTimeout = 5 * time.Minute
+
 func DownloadHttpTo(url string, filepath string) error {
+	return DownloadHttpToCtx(context.Background(), url, filepath)
+}
+
+func DownloadHttpToCtx(ctx context.Context, url string, filepath string) error {
 	log.Info("http download:", url)
 
 	out, err := os.Create(filepath)
@@ -20,7 +31,7 @@ func DownloadHttpTo(url string, filepath string) error {
 	}
 	defer out.Close()
 
-	resp, err := http.Get(url)
+	resp, err := get(ctx, url)
 	if err != nil {
 		log.Err(err, "Could not request url.")
 		return err
@@ -43,9 +54,13 @@ func DownloadHttpTo(url string, filepath string) error {
 }
 
 func DownloadHttp(url string) ([]byte, error) {
+	return DownloadHttpCtx(context.Background(), url)
+}
+
+func DownloadHttpCtx(ctx context.Context, url string) ([]byte, error) {
 	log.Info("http download:", url)
 
-	resp, err := http.Get(url)
+	resp, err := get(ctx, url)
 	if err != nil {
 		log.Err(err, "Could not request url.")
 		return nil, err
@@ -63,6 +78,10 @@ func DownloadHttp(url string) ([]byte, error) {
 }
 
 func UploadHttpFrom(url string, filepath string, contentType string) error {
+	return UploadHttpFromCtx(context.Background(), url, filepath, contentType)
+}
+
+func UploadHttpFromCtx(ctx context.Context, url string, filepath string, contentType string) error {
 	log.Info("http upload", filepath, "to", url, "with content type", contentType)
 
 	file, err := os.Open(filepath)
@@ -71,15 +90,15 @@ func UploadHttpFrom(url string, filepath string, contentType string) error {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPut, url, file)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, file)
 	if err != nil {
+		file.Close()
 		log.Err(err, "Could not create put request.")
 		return err
 	}
 	req.Header.Add("Content-Type", contentType)
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	res, err := fileClient().Do(req)
 	if err != nil {
 		log.Err(err, "Could not send request.")
 		return err
@@ -89,17 +108,20 @@ func UploadHttpFrom(url string, filepath string, contentType string) error {
 }
 
 func UploadHttp(url, contentType string, data []byte) error {
+	return UploadHttpCtx(context.Background(), url, contentType, data)
+}
+
+func UploadHttpCtx(ctx context.Context, url, contentType string, data []byte) error {
 	log.Info("http upload", url, "with content type", contentType)
 
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
 	if err != nil {
 		log.Err(err, "Could not create put request.")
 		return err
 	}
 	req.Header.Add("Content-Type", contentType)
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	res, err := fileClient().Do(req)
 	if err != nil {
 		log.Err(err, "Could not send request.")
 		return err
@@ -107,3 +129,17 @@ func UploadHttp(url, contentType string, data []byte) error {
 	defer res.Body.Close()
 	return nil
 }
+
+// intern
+
+func get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fileClient().Do(req)
+}
+
+func fileClient() *http.Client {
+	return &http.Client{Timeout: FileTimeout}
+}
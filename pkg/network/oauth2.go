@@ -0,0 +1,186 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2Config configures the token endpoint of an OAuth2 authorization
+// server.
+type OAuth2Config struct {
+	TokenUrl     string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	Audience     string        // sent as audience parameter if set
+	AuthInBody   bool          // send client credentials as form values instead of basic auth
+	ExpiryDelta  time.Duration // tokens are refreshed this long before they expire
+	HttpClient   *http.Client  // http.DefaultClient if nil
+}
+
+func DefaultOAuth2Config(tokenUrl, clientId, clientSecret string) OAuth2Config {
+	return OAuth2Config{
+		TokenUrl:     tokenUrl,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		ExpiryDelta:  30 * time.Second,
+	}
+}
+
+// Token is the response of a token endpoint.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int64     `json:"expires_in"`
+	Scope        string    `json:"scope"`
+	Expiry       time.Time `json:"-"` // zero if the token doesn't expire
+}
+
+// TokenSource provides access tokens, see Client.SetTokenSource.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// OAuth2 fetches and caches access tokens of the client credentials or
+// refresh token flow. Tokens are refreshed ExpiryDelta before they expire.
+// Concurrent callers wait for a single fetch.
+type OAuth2 struct {
+	config       OAuth2Config
+	grant        string
+	mu           sync.Mutex
+	token        *Token
+	refreshToken string
+}
+
+// NewClientCredentials returns a source using the client credentials grant.
+func NewClientCredentials(config OAuth2Config) *OAuth2 {
+	return &OAuth2{config: config, grant: "client_credentials"}
+}
+
+// NewRefreshToken returns a source using the refresh token grant. A refresh
+// token returned by the server replaces refreshToken.
+func NewRefreshToken(config OAuth2Config, refreshToken string) *OAuth2 {
+	return &OAuth2{config: config, grant: "refresh_token", refreshToken: refreshToken}
+}
+
+// Token returns the cached token or fetches a new one if it expires within
+// ExpiryDelta.
+func (o *OAuth2) Token(ctx context.Context) (*Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != nil && !o.token.expires(o.config.ExpiryDelta) {
+		return o.token, nil
+	}
+	token, err := o.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.token = token
+	return token, nil
+}
+
+// Invalidate drops the cached token if it is accessToken, e.g. after it got
+// rejected. Tokens fetched meanwhile are kept.
+func (o *OAuth2) Invalidate(accessToken string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != nil && o.token.AccessToken == accessToken {
+		o.token = nil
+	}
+}
+
+// AuthFn adapts the source for NewClient. As Client only calls it again
+// after a 401, a token already returned is invalidated on the next call.
+// Client.SetTokenSource refreshes tokens before they expire instead.
+func (o *OAuth2) AuthFn() AuthFn {
+	var mu sync.Mutex
+	last := ""
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if last != "" {
+			o.Invalidate(last)
+		}
+		token, err := o.Token(context.Background())
+		if err != nil {
+			return "", err
+		}
+		last = token.AccessToken
+		return last, nil
+	}
+}
+
+// intern
+
+func (t *Token) expires(delta time.Duration) bool {
+	return !t.Expiry.IsZero() && time.Now().Add(delta).After(t.Expiry)
+}
+
+func (o *OAuth2) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {o.grant}}
+	if o.grant == "refresh_token" {
+		if o.refreshToken == "" {
+			return nil, errors.New("no refresh token")
+		}
+		form.Set("refresh_token", o.refreshToken)
+	}
+	if len(o.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.config.Scopes, " "))
+	}
+	if o.config.Audience != "" {
+		form.Set("audience", o.config.Audience)
+	}
+	if o.config.AuthInBody {
+		form.Set("client_id", o.config.ClientId)
+		form.Set("client_secret", o.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.config.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !o.config.AuthInBody {
+		req.SetBasicAuth(url.QueryEscape(o.config.ClientId), url.QueryEscape(o.config.ClientSecret))
+	}
+
+	client := o.config.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+	var token Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("no access token in response")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = start.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	if token.RefreshToken != "" && o.grant == "refresh_token" {
+		o.refreshToken = token.RefreshToken
+	}
+	return &token, nil
+}
@@ -0,0 +1,177 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer issues access-<n> tokens and rotates refresh tokens.
+func tokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			id, secret = r.FormValue("client_id"), r.FormValue("client_secret")
+		}
+		if id != "id" || secret != "secret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("scope") != "read write" || r.FormValue("audience") != "api" {
+			http.Error(w, `{"error":"invalid_scope"}`, http.StatusBadRequest)
+			return
+		}
+		n := strconv.Itoa(int(issued.Add(1)))
+		token := map[string]any{"access_token": "access-" + n, "token_type": "Bearer", "expires_in": expiresIn}
+		switch r.FormValue("grant_type") {
+		case "client_credentials":
+		case "refresh_token":
+			if r.FormValue("refresh_token") != "refresh-"+strconv.Itoa(int(issued.Load()-1)) {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			token["refresh_token"] = "refresh-" + n
+		default:
+			http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(token)
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func testOAuth2Config(tokenUrl string) OAuth2Config {
+	config := DefaultOAuth2Config(tokenUrl, "id", "secret")
+	config.Scopes = []string{"read", "write"}
+	config.Audience = "api"
+	return config
+}
+
+func TestClientCredentials(t *testing.T) {
+	server, issued := tokenServer(t, 3600)
+	source := NewClientCredentials(testOAuth2Config(server.URL))
+
+	// cached
+	for range 2 {
+		token, err := source.Token(context.Background())
+		if err != nil || token.AccessToken != "access-1" || token.Expiry.Before(time.Now().Add(59*time.Minute)) {
+			t.Error("TestClientCredentials:: unexpected token", token, err)
+		}
+	}
+
+	// concurrent callers share a fetch
+	source.Invalidate("access-1")
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := source.Token(context.Background()); err != nil || token.AccessToken != "access-2" {
+				t.Error("TestClientCredentials:: unexpected concurrent token", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if issued.Load() != 2 {
+		t.Error("TestClientCredentials:: expected 2 fetches", issued.Load())
+	}
+
+	// credentials in body
+	config := testOAuth2Config(server.URL)
+	config.AuthInBody = true
+	if _, err := NewClientCredentials(config).Token(context.Background()); err != nil {
+		t.Error("TestClientCredentials:: credentials in body failed", err)
+	}
+
+	// rejected
+	config.ClientSecret = "wrong"
+	_, err := NewClientCredentials(config).Token(context.Background())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Error("TestClientCredentials:: expected http error", err)
+	}
+}
+
+func TestClientCredentialsExpiry(t *testing.T) {
+	server, issued := tokenServer(t, 60)
+	config := testOAuth2Config(server.URL)
+	config.ExpiryDelta = 2 * time.Minute
+	source := NewClientCredentials(config)
+
+	// expires within delta, refreshed on each call
+	source.Token(context.Background())
+	token, err := source.Token(context.Background())
+	if err != nil || token.AccessToken != "access-2" || issued.Load() != 2 {
+		t.Error("TestClientCredentialsExpiry:: expected proactive refresh", token, err)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	server, _ := tokenServer(t, 3600)
+	source := NewRefreshToken(testOAuth2Config(server.URL), "refresh-0")
+
+	token, err := source.Token(context.Background())
+	if err != nil || token.AccessToken != "access-1" {
+		t.Fatal("TestRefreshToken:: unexpected token", token, err)
+	}
+	// rotated refresh token is used
+	source.Invalidate(token.AccessToken)
+	token, err = source.Token(context.Background())
+	if err != nil || token.AccessToken != "access-2" || source.refreshToken != "refresh-2" {
+		t.Error("TestRefreshToken:: expected rotated refresh token", token, err)
+	}
+
+	if _, err := NewRefreshToken(testOAuth2Config(server.URL), "").Token(context.Background()); err == nil {
+		t.Error("TestRefreshToken:: expected missing refresh token error")
+	}
+}
+
+func TestClientTokenSource(t *testing.T) {
+	tokens, _ := tokenServer(t, 3600)
+	var revoked atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if auth == "" || (revoked.Load() && auth == "Bearer access-1") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(auth))
+	}))
+	defer server.Close()
+
+	// auth function
+	source := NewClientCredentials(testOAuth2Config(tokens.URL))
+	c := NewClient(nil, "", "", source.AuthFn(), false)
+	data, err := c.RequestData(http.MethodGet, server.URL, nil, nil, true)
+	if err != nil || string(data) != "Bearer access-1" {
+		t.Error("TestClientTokenSource:: unexpected auth", string(data), err)
+	}
+	// rejected token is fetched again
+	revoked.Store(true)
+	data, err = c.RequestData(http.MethodGet, server.URL, nil, nil, true)
+	if err != nil || string(data) != "Bearer access-2" {
+		t.Error("TestClientTokenSource:: expected new token after 401", string(data), err)
+	}
+
+	// token source
+	revoked.Store(false)
+	config := testOAuth2Config(tokens.URL)
+	config.ExpiryDelta = 2 * time.Hour
+	c = NewClient(nil, "", "", nil, false)
+	c.SetTokenSource(NewClientCredentials(config))
+	first, _ := c.RequestData(http.MethodGet, server.URL, nil, nil, true)
+	second, err := c.RequestData(http.MethodGet, server.URL, nil, nil, true)
+	if err != nil || string(first) == string(second) {
+		t.Error("TestClientTokenSource:: expiring token should be refreshed", string(first), string(second), err)
+	}
+}
@@ -2,6 +2,7 @@ package network
 
 import (
 	"bufio"
+	"context"
 	"io"
 	"net/http"
 	"os"
@@ -19,21 +20,26 @@ type StreamInfo struct {
 type Requester struct {
 	config     RequestConfig
 	streamInfo StreamInfo
+	client     *http.Client
 }
 
 func NewRequester(config RequestConfig) *Requester {
-	return &Requester{config: config}
+	return &Requester{config: config, client: &http.Client{Timeout: config.Timeout * time.Second}}
 }
 
 // request
 
 func (r *Requester) Get(url string, printBody bool) ([]byte, error) {
+	return r.GetCtx(context.Background(), url, printBody)
+}
+
+func (r *Requester) GetCtx(ctx context.Context, url string, printBody bool) ([]byte, error) {
 	if r.config.LogLevel > 0 {
 		log.Info("Get:", url)
 	}
 
 	// request
-	resp, err := r.Request(http.MethodGet, url, false, nil)
+	resp, err := r.RequestCtx(ctx, http.MethodGet, url, false, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -53,12 +59,16 @@ func (r *Requester) Get(url string, printBody bool) ([]byte, error) {
 }
 
 func (r *Requester) Delete(url string) (bool, error) {
+	return r.DeleteCtx(context.Background(), url)
+}
+
+func (r *Requester) DeleteCtx(ctx context.Context, url string) (bool, error) {
 	if r.config.LogLevel > 0 {
 		log.Info("Delete:", url)
 	}
 
 	// request
-	resp, err := r.Request(http.MethodDelete, url, false, nil)
+	resp, err := r.RequestCtx(ctx, http.MethodDelete, url, false, nil)
 	if err != nil {
 		return false, err
 	}
@@ -76,6 +86,12 @@ func (r *Requester) Delete(url string) (bool, error) {
 // stream
 
 func (r *Requester) ReadStream(url string, dumpToFile string) error {
+	return r.ReadStreamCtx(context.Background(), url, dumpToFile)
+}
+
+// ReadStreamCtx reads until the stream ends, MaxBytes were read or ctx is
+// done.
+func (r *Requester) ReadStreamCtx(ctx context.Context, url string, dumpToFile string) error {
 	if r.config.LogLevel > 0 {
 		log.Info("Read stream:", url)
 	}
@@ -83,7 +99,7 @@ func (r *Requester) ReadStream(url string, dumpToFile string) error {
 	r.streamInfo.Url = url
 
 	// request
-	resp, err := r.Request(http.MethodGet, url, true, nil)
+	resp, err := r.RequestCtx(ctx, http.MethodGet, url, true, nil)
 	if err != nil {
 		return err
 	}
@@ -105,7 +121,7 @@ func (r *Requester) ReadStream(url string, dumpToFile string) error {
 	// read data
 	reader := bufio.NewReader(resp.Body)
 	for {
-		line, _ := reader.ReadBytes('\n')
+		line, readErr := reader.ReadBytes('\n')
 		if writeToFile {
 			file.Write(line)
 		}
@@ -115,6 +131,15 @@ func (r *Requester) ReadStream(url string, dumpToFile string) error {
 			log.Info("Stop: Max bytes read", r.streamInfo.BytesRead)
 			break
 		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return readErr
+		}
 	}
 
 	return err
@@ -129,8 +154,12 @@ func (r *Requester) PrintStreamInfo() {
 // common
 
 func (r *Requester) Request(method string, url string, isStream bool, body io.Reader) (*http.Response, error) {
+	return r.RequestCtx(context.Background(), method, url, isStream, body)
+}
+
+func (r *Requester) RequestCtx(ctx context.Context, method string, url string, isStream bool, body io.Reader) (*http.Response, error) {
 	// build request
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		log.Err(err, "Request error.")
 		return nil, err
@@ -142,14 +171,14 @@ func (r *Requester) Request(method string, url string, isStream bool, body io.Re
 		req.Header.Add("Authorization", bearer)
 
 		if r.config.LogLevel > 2 {
-			log.Info("Bearer auth:", bearer)
+			log.Info("Bearer auth.")
 		}
 	} else if r.config.Auth.BasicUser != "" && r.config.Auth.BasicPassword != "" {
 		// add basic auth
 		req.SetBasicAuth(r.config.Auth.BasicUser, r.config.Auth.BasicPassword)
 
 		if r.config.LogLevel > 2 {
-			log.Info("Basic auth:", r.config.Auth.BasicUser, r.config.Auth.BasicPassword)
+			log.Info("Basic auth:", "user="+r.config.Auth.BasicUser)
 		}
 	}
 
@@ -163,8 +192,7 @@ func (r *Requester) Request(method string, url string, isStream bool, body io.Re
 	}
 
 	// request
-	client := &http.Client{Timeout: r.config.Timeout * time.Second}
-	resp, err := client.Do(req)
+	resp, err := r.client.Do(req)
 	if err != nil {
 		log.Err(err, "Client error.")
 		return nil, err
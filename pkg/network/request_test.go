@@ -0,0 +1,29 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequesterBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + string(body)))
+	}))
+	defer server.Close()
+
+	requester := NewRequester(DefaultRequestConfig())
+	resp, err := requester.RequestCtx(context.Background(), http.MethodPost, server.URL, false, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal("TestRequesterBody:: request failed", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if string(data) != "POST payload" {
+		t.Error("TestRequesterBody:: body not sent", string(data))
+	}
+}
@@ -0,0 +1,176 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures retries of Client requests. Attempts which fail
+// with a retryable error or status code are repeated after an exponential
+// backoff. A Retry-After header of the response replaces the backoff.
+//
+// Only idempotent methods and requests with an Idempotency-Key header are
+// retried, unless RetryNonIdempotent is set. Request bodies are buffered
+// only if the request may be retried or re-authenticated.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first, <= 1 disables retries
+	Backoff     time.Duration // delay before the first retry, doubled per retry
+	MaxBackoff  time.Duration // max delay, also caps Retry-After
+	Jitter      float64       // randomizes delays by +-Jitter*delay, 0..1
+	RetryStatus []int         // status codes which are retried
+	// RetryErr reports if a transport error is retried. IsRetryableError is
+	// used if nil.
+	RetryErr func(err error) bool
+	// MaxReauth bounds how often the token is cleared and fetched again
+	// after a 401. Re-auth attempts don't count as attempts.
+	MaxReauth int
+	// RetryNonIdempotent retries e.g. POST and PATCH requests as well, which
+	// may execute them twice if an attempt reached the server.
+	RetryNonIdempotent bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Jitter:      0.2,
+		RetryStatus: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		MaxReauth:   1,
+	}
+}
+
+// NoRetryPolicy sends requests once and re-authenticates once after a 401.
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1, MaxReauth: 1}
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a context which makes Client requests use policy
+// instead of the policy of the client.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// IsRetryableError reports if err is a timeout, a reset or refused
+// connection or an unexpected end of the response.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// intern
+
+// policyFor returns the policy set by WithRetryPolicy or def.
+func policyFor(ctx context.Context, def RetryPolicy) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return def
+}
+
+// canRetry reports if a request with method and headers may be retried.
+func (p RetryPolicy) canRetry(method string, headers Headers) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	return p.RetryNonIdempotent || isIdempotent(method, headers)
+}
+
+func isIdempotent(method string, headers Headers) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == "Idempotency-Key" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryErr(err error) bool {
+	if p.RetryErr != nil {
+		return p.RetryErr(err)
+	}
+	return IsRetryableError(err)
+}
+
+func (p RetryPolicy) retryStatus(status int) bool {
+	return slices.Contains(p.RetryStatus, status)
+}
+
+// delay returns the wait before retry n (starting at 1). retryAfter of the
+// last response is used if set.
+func (p RetryPolicy) delay(n int, retryAfter time.Duration) time.Duration {
+	d := retryAfter
+	if d <= 0 {
+		d = p.Backoff
+		for i := 1; i < n && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+			d *= 2
+		}
+		if p.Jitter > 0 {
+			d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return max(d, 0)
+}
+
+// retryAfter parses the Retry-After header as seconds or http date.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain discards the rest of the body to allow reusing the connection.
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}